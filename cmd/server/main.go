@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,13 +13,23 @@ import (
 	"ocean-marketing/internal/config"
 	"ocean-marketing/internal/handler"
 	"ocean-marketing/internal/middleware"
+	"ocean-marketing/internal/pkg/alert"
 	"ocean-marketing/internal/pkg/database"
+	"ocean-marketing/internal/pkg/eventbus"
+	"ocean-marketing/internal/pkg/health"
+	"ocean-marketing/internal/pkg/job"
+	"ocean-marketing/internal/pkg/lifecycle"
 	"ocean-marketing/internal/pkg/logger"
 	"ocean-marketing/internal/pkg/migration"
+	"ocean-marketing/internal/pkg/outbox"
 	"ocean-marketing/internal/pkg/redis"
+	"ocean-marketing/internal/pkg/scheduler"
 	"ocean-marketing/internal/pkg/tracer"
+	"ocean-marketing/internal/pkg/version"
 	"ocean-marketing/internal/router"
+	"ocean-marketing/pkg/email"
 	"ocean-marketing/pkg/jwt"
+	"ocean-marketing/pkg/mq"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -41,14 +53,25 @@ import (
 // @in header
 // @name Authorization
 func main() {
+	// 子命令
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+
 	// 初始化配置
 	cfg := config.Init()
 
 	// 初始化日志
 	logger.Init(cfg.Log)
+	logger.InitSLS(cfg.Aliyun)
 
 	// 初始化数据库
 	database.Init(cfg.Database)
+	lifecycle.Append(lifecycle.Hook{
+		Name:    "database",
+		OnStop:  func(ctx context.Context) error { return database.Close() },
+		Timeout: 5 * time.Second,
+	})
 
 	// 数据库迁移
 	migration.AutoMigrate()
@@ -57,21 +80,125 @@ func main() {
 
 	// 初始化Redis
 	redis.Init(cfg.Redis)
+	lifecycle.Append(lifecycle.Hook{
+		Name:    "redis",
+		OnStop:  func(ctx context.Context) error { return redis.Close() },
+		Timeout: 5 * time.Second,
+	})
 
-	// 初始化链路追踪
+	// 初始化链路追踪，停止时刷新缓冲中的span
 	tracer.Init(cfg.Tracer)
+	lifecycle.Append(lifecycle.Hook{
+		Name:    "tracer",
+		OnStop:  tracer.Shutdown,
+		Timeout: 5 * time.Second,
+	})
+
+	// 初始化告警，晚于其他组件停止，关闭过程中产生的告警也能发出
+	alert.Init(cfg.Alert, cfg.Email)
+	lifecycle.Append(lifecycle.Hook{
+		Name:    "alert",
+		OnStop:  alert.Close,
+		Timeout: 10 * time.Second,
+	})
 
 	// 初始化JWT
 	jwt.Init(cfg.JWT)
 
+	// 初始化消息队列
+	var mqClient *mq.Client
+	if cfg.Outbox.Enabled || cfg.Event.ForwardToMQ {
+		var err error
+		mqClient, err = mq.NewClient(cfg.MQ)
+		if err != nil {
+			logger.Fatal("初始化消息队列失败", zap.Error(err))
+		}
+		lifecycle.Append(lifecycle.Hook{
+			Name:    "mq",
+			OnStop:  func(ctx context.Context) error { return mqClient.Close() },
+			Timeout: 5 * time.Second,
+		})
+	}
+
+	// 事务发件箱投递器
+	if cfg.Outbox.Enabled {
+		relay := outbox.NewRelay(database.GetDB(), mqClient, cfg.Outbox)
+		lifecycle.Append(lifecycle.Hook{
+			Name: "outbox_relay",
+			OnStart: func(ctx context.Context) error {
+				relay.Start()
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				relay.Stop()
+				return nil
+			},
+		})
+	}
+
+	// 领域事件转发到MQ（发件箱已负责投递时不重复转发）
+	if cfg.Event.ForwardToMQ {
+		if cfg.Outbox.Enabled {
+			logger.Warn("已开启发件箱，忽略 event.forward_to_mq")
+		} else {
+			eventbus.SubscribeAsync(eventbus.AllEvents, eventbus.NewMQForwarder(mqClient, cfg.Event.Exchange))
+		}
+	}
+
+	// 停止时等待异步事件订阅者处理完队列
+	lifecycle.Append(lifecycle.Hook{
+		Name: "eventbus",
+		OnStop: func(ctx context.Context) error {
+			eventbus.Close()
+			return nil
+		},
+	})
+
+	// 后台任务执行池
+	if cfg.Job.Enabled {
+		jobPool := job.NewPool(database.GetDB(), cfg.Job)
+		lifecycle.Append(lifecycle.Hook{
+			Name: "job_pool",
+			OnStart: func(ctx context.Context) error {
+				jobPool.Start()
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				jobPool.Stop()
+				return nil
+			},
+		})
+	}
+
+	// 定时任务调度器
+	if cfg.Scheduler.Enabled {
+		registerScheduledTasks(cfg)
+
+		sched := scheduler.New(cfg.Scheduler, redis.GetClient())
+		lifecycle.Append(lifecycle.Hook{
+			Name:    "scheduler",
+			OnStart: func(ctx context.Context) error { return sched.Start() },
+			OnStop: func(ctx context.Context) error {
+				sched.Stop()
+				return nil
+			},
+		})
+	}
+
+	// 注册健康检查
+	registerHealthChecks(cfg, mqClient)
+
 	// 初始化handlers
 	handler.Init(cfg)
 
 	// 设置Gin模式
 	gin.SetMode(cfg.App.Mode)
 
-	// 创建Gin引擎
+	// 创建Gin引擎，只信任配置的代理转发的客户端IP
 	r := gin.New()
+	if err := r.SetTrustedProxies(cfg.App.TrustedProxies); err != nil {
+		logger.Fatal("设置可信代理失败", zap.Error(err))
+	}
 
 	// 注册中间件
 	middleware.Register(r, cfg)
@@ -83,19 +210,49 @@ func main() {
 	// 注册路由
 	router.Register(r, cfg)
 
-	// 创建HTTP服务器
+	// 运维端点：配置了 admin.addr 时在独立端口提供，否则挂在主端口并要求basic认证或IP白名单
+	if cfg.Admin.Addr == "" {
+		router.RegisterInternal(r.Group("", middleware.AdminAuth(cfg.Admin)), cfg.Admin)
+	} else {
+		registerAdminServer(cfg)
+	}
+
+	// 监听配置文件变化，热更新配置项实时生效
+	if err := config.Watch(reportReload); err != nil {
+		logger.Error("监听配置文件失败，配置热更新不可用", zap.Error(err))
+	}
+
+	// HTTP服务器最后启动、最先停止
 	srv := &http.Server{
 		Addr:    cfg.App.Port,
 		Handler: r,
 	}
+	lifecycle.Append(lifecycle.Hook{
+		Name: "http_server",
+		OnStart: func(ctx context.Context) error {
+			// 同步监听端口，端口被占用时直接返回错误
+			ln, err := net.Listen("tcp", srv.Addr)
+			if err != nil {
+				return err
+			}
+			go func() {
+				logger.Info("服务器启动",
+					zap.String("addr", cfg.App.Port),
+					zap.String("version", version.Version),
+					zap.String("commit", version.Commit))
+				if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+					logger.Fatal("服务器运行失败", zap.Error(err))
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error { return srv.Shutdown(ctx) },
+	})
 
-	// 启动服务器
-	go func() {
-		logger.Info("服务器启动", zap.String("addr", cfg.App.Port))
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Fatal("服务器启动失败", zap.Error(err))
-		}
-	}()
+	if err := lifecycle.Start(context.Background()); err != nil {
+		logger.Fatal("服务启动失败", zap.Error(err))
+	}
+	lifecycle.SetReady(true)
 
 	// 等待中断信号以优雅地关闭服务器
 	quit := make(chan os.Signal, 1)
@@ -103,13 +260,134 @@ func main() {
 	<-quit
 	logger.Info("服务器关闭中...")
 
-	// 5秒的超时时间
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// 先标记为未就绪，等待负载均衡摘除流量后再关闭连接
+	lifecycle.SetReady(false)
+	if delay := time.Duration(cfg.App.ShutdownDelay) * time.Second; delay > 0 {
+		logger.Info("等待摘除流量", zap.Duration("delay", delay))
+		time.Sleep(delay)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.App.ShutdownTimeout)*time.Second)
 	defer cancel()
 
-	if err := srv.Shutdown(ctx); err != nil {
-		logger.Fatal("服务器关闭失败", zap.Error(err))
+	if err := lifecycle.Stop(ctx); err != nil {
+		logger.Error("部分组件未能正常关闭", zap.Error(err))
 	}
 
 	logger.Info("服务器已关闭")
+
+	// 投递剩余日志到SLS
+	flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer flushCancel()
+	if err := logger.CloseSLS(flushCtx); err != nil {
+		fmt.Fprintf(os.Stderr, "投递剩余日志到SLS超时: %v\n", err)
+	}
+	logger.Sync()
+}
+
+// registerAdminServer 在独立端口提供运维端点，先于主服务启动、晚于主服务停止，关闭过程中仍可查看指标
+func registerAdminServer(cfg *config.Config) {
+	r := gin.New()
+	r.Use(middleware.Recovery())
+	router.RegisterInternal(r.Group("", middleware.AdminAuth(cfg.Admin)), cfg.Admin)
+
+	srv := &http.Server{
+		Addr:    cfg.Admin.Addr,
+		Handler: r,
+	}
+	lifecycle.Append(lifecycle.Hook{
+		Name: "admin_server",
+		OnStart: func(ctx context.Context) error {
+			ln, err := net.Listen("tcp", srv.Addr)
+			if err != nil {
+				return err
+			}
+			go func() {
+				logger.Info("运维端点启动", zap.String("addr", cfg.Admin.Addr))
+				if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+					logger.Error("运维端点运行失败", zap.Error(err))
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error { return srv.Shutdown(ctx) },
+	})
+}
+
+// registerHealthChecks 注册各依赖的健康检查，关键依赖失败时服务未就绪
+func registerHealthChecks(cfg *config.Config, mqClient *mq.Client) {
+	health.SetCacheTTL(time.Duration(cfg.Health.CacheTTL) * time.Second)
+	timeout := time.Duration(cfg.Health.Timeout) * time.Second
+
+	health.Register(health.Check{Name: "database", Check: database.Ping, Timeout: timeout, Critical: true})
+	health.Register(health.Check{Name: "redis", Check: redis.Ping, Timeout: timeout, Critical: true})
+	health.Register(health.Check{Name: "tracer", Check: tracer.Ping, Timeout: timeout})
+
+	if mqClient != nil {
+		// 发件箱失败后会重试，MQ不可用时服务降级但仍可处理请求
+		health.Register(health.Check{Name: "mq", Check: mqClient.Ping, Timeout: timeout})
+	}
+
+	if cfg.Email.Host != "" {
+		health.Register(health.Check{Name: "smtp", Check: email.NewClient(cfg.Email).Ping, Timeout: timeout})
+	}
+}
+
+// registerScheduledTasks 注册内置定时任务
+func registerScheduledTasks(cfg *config.Config) {
+	scheduler.Register(scheduler.Task{
+		Name:         "job_cleanup",
+		Spec:         "@daily",
+		MissedPolicy: scheduler.MissedRunOnce,
+		Run: func(ctx context.Context) error {
+			before := time.Now().Add(-time.Duration(cfg.Job.Retention) * time.Second)
+			count, err := job.Cleanup(ctx, before)
+			if err != nil {
+				return err
+			}
+			logger.Info("清理已结束任务", zap.Int64("count", count))
+			return nil
+		},
+	})
+}
+
+// runCommand 执行子命令，返回进程退出码
+func runCommand(args []string) int {
+	switch {
+	case len(args) >= 2 && args[0] == "config" && args[1] == "print":
+		redact := false
+		for _, arg := range args[2:] {
+			if arg == "--redact" {
+				redact = true
+			}
+		}
+
+		config.Init()
+		if err := config.Print(os.Stdout, redact); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	case args[0] == "version":
+		info := version.Get()
+		fmt.Printf("version: %s\ncommit: %s\nbuild time: %s\ngo version: %s\n", info.Version, info.Commit, info.BuildTime, info.GoVersion)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "未知命令: %v\n用法:\n  server                        启动服务\n  server config print [--redact]  输出实际生效的配置\n  server version                输出版本信息\n", args)
+		return 2
+	}
+}
+
+// reportReload 输出配置重载结果
+func reportReload(result config.ReloadResult) {
+	if result.Err != nil {
+		logger.Error("配置重载失败，继续使用旧配置", zap.Error(result.Err))
+		return
+	}
+	if len(result.Ignored) > 0 {
+		logger.Warn("以下配置变更需重启后生效，已忽略", zap.Strings("sections", result.Ignored))
+	}
+	if len(result.Applied) > 0 {
+		logger.Info("配置已热更新", zap.Strings("keys", result.Applied))
+	}
 }
@@ -1,8 +1,12 @@
 package config
 
 import (
+	"fmt"
+	"os"
 	"strings"
+	"sync/atomic"
 
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 )
 
@@ -15,8 +19,22 @@ type Config struct {
 	JWT      JWTConfig      `mapstructure:"jwt"`
 	Email    EmailConfig    `mapstructure:"email"`
 	Tracer   TracerConfig   `mapstructure:"tracer"`
-	Feishu   FeishuConfig   `mapstructure:"feishu"`
-	MQ       MQConfig       `mapstructure:"mq"`
+	// 已迁移到 alert.feishu，仅为兼容旧配置保留
+	Feishu      FeishuConfig      `mapstructure:"feishu"`
+	Alert       AlertConfig       `mapstructure:"alert"`
+	MQ          MQConfig          `mapstructure:"mq"`
+	Outbox      OutboxConfig      `mapstructure:"outbox"`
+	Event       EventConfig       `mapstructure:"event"`
+	Job         JobConfig         `mapstructure:"job"`
+	Scheduler   SchedulerConfig   `mapstructure:"scheduler"`
+	Security    SecurityConfig    `mapstructure:"security"`
+	Performance PerformanceConfig `mapstructure:"performance"`
+	Health      HealthConfig      `mapstructure:"health"`
+	Aliyun      AliyunConfig      `mapstructure:"aliyun"`
+	Metrics     MetricsConfig     `mapstructure:"metrics"`
+	Admin       AdminConfig       `mapstructure:"admin"`
+	// 功能开关，支持热更新
+	Features map[string]bool `mapstructure:"features"`
 }
 
 // AppConfig 应用配置
@@ -24,6 +42,14 @@ type AppConfig struct {
 	Name string `mapstructure:"name"`
 	Port string `mapstructure:"port"`
 	Mode string `mapstructure:"mode"`
+	// 管理接口允许访问的用户名
+	AdminUsers []string `mapstructure:"admin_users"`
+	// 可信的反向代理IP或CIDR，只有来自这些地址的请求才读取 X-Forwarded-For / X-Real-IP，为空时使用TCP对端地址
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+	// 优雅关闭的总超时（秒）
+	ShutdownTimeout int `mapstructure:"shutdown_timeout"`
+	// 收到退出信号后先标记未就绪，等待该时间（秒）让负载均衡摘除流量再关闭
+	ShutdownDelay int `mapstructure:"shutdown_delay"`
 }
 
 // DatabaseConfig 数据库配置
@@ -38,6 +64,8 @@ type DatabaseConfig struct {
 	MaxIdleConns    int    `mapstructure:"max_idle_conns"`
 	MaxOpenConns    int    `mapstructure:"max_open_conns"`
 	ConnMaxLifetime int    `mapstructure:"conn_max_lifetime"`
+	// 慢查询阈值（毫秒），超过时记录warn日志，0表示不记录
+	SlowThreshold int `mapstructure:"slow_threshold"`
 	// 阿里云RDS相关配置
 	SSLMode      string `mapstructure:"ssl_mode"`
 	Timeout      int    `mapstructure:"timeout"`
@@ -52,6 +80,9 @@ type RedisConfig struct {
 	Port     int    `mapstructure:"port"`
 	Password string `mapstructure:"password"`
 	DB       int    `mapstructure:"db"`
+	// 连接池配置，0表示使用go-redis默认值
+	PoolSize     int `mapstructure:"pool_size"`
+	MinIdleConns int `mapstructure:"min_idle_conns"`
 }
 
 // LogConfig 日志配置
@@ -63,6 +94,29 @@ type LogConfig struct {
 	MaxAge     int    `mapstructure:"max_age"`
 	MaxBackups int    `mapstructure:"max_backups"`
 	Compress   bool   `mapstructure:"compress"`
+	// HTTP 请求日志，支持热更新
+	HTTP HTTPLogConfig `mapstructure:"http"`
+	// Modules 按模块覆盖日志级别，如 mq: debug，支持热更新
+	Modules map[string]string `mapstructure:"modules"`
+}
+
+// HTTPLogConfig 请求日志配置
+type HTTPLogConfig struct {
+	// BodyMode 请求/响应体记录方式: off, errors（状态码>=400或有错误时）, sampled（按比例）, all
+	BodyMode string `mapstructure:"body_mode"`
+	// SampleRate sampled 模式下的采样比例，0-1
+	SampleRate float64 `mapstructure:"sample_rate"`
+	// MaxBodySize 记录的请求/响应体最大字节数，超出部分截断
+	MaxBodySize int `mapstructure:"max_body_size"`
+	// ContentTypes 记录请求/响应体的内容类型前缀，其他类型（如文件上传）只记录长度
+	ContentTypes []string `mapstructure:"content_types"`
+	// RedactFields 需要脱敏的字段：不含点号时匹配任意层级的同名字段，
+	// 含点号时从根开始按路径匹配，* 匹配任意一级，数组元素不占路径层级
+	RedactFields []string `mapstructure:"redact_fields"`
+	// LogHeaders 是否记录请求头
+	LogHeaders bool `mapstructure:"log_headers"`
+	// RedactHeaders 需要脱敏的请求头
+	RedactHeaders []string `mapstructure:"redact_headers"`
 }
 
 // JWTConfig JWT配置
@@ -83,15 +137,92 @@ type EmailConfig struct {
 
 // TracerConfig 链路追踪配置
 type TracerConfig struct {
-	ServiceName string  `mapstructure:"service_name"`
-	AgentHost   string  `mapstructure:"agent_host"`
-	AgentPort   int     `mapstructure:"agent_port"`
-	SampleRate  float64 `mapstructure:"sample_rate"`
+	Enabled     bool   `mapstructure:"enabled"`
+	ServiceName string `mapstructure:"service_name"`
+	// 导出方式: otlp_grpc, otlp_http, stdout, memory, none
+	Exporter string `mapstructure:"exporter"`
+	// OTLP接收端地址 host:port，gRPC默认端口4317，HTTP默认端口4318
+	Endpoint string `mapstructure:"endpoint"`
+	// 不使用TLS连接接收端
+	Insecure bool `mapstructure:"insecure"`
+	// 导出时附带的请求头，如鉴权token
+	Headers map[string]string `mapstructure:"headers"`
+	// 采样率 0-1
+	SampleRate float64 `mapstructure:"sample_rate"`
+	// 遵循上游的采样决定，只对新的trace按采样率采样
+	ParentBased bool `mapstructure:"parent_based"`
+	// 导出超时（秒）
+	Timeout int `mapstructure:"timeout"`
 }
 
-// FeishuConfig 飞书配置
+// AliyunConfig 阿里云配置
+type AliyunConfig struct {
+	Region          string    `mapstructure:"region"`
+	AccessKeyID     string    `mapstructure:"access_key_id"`
+	AccessKeySecret string    `mapstructure:"access_key_secret"`
+	SLS             SLSConfig `mapstructure:"sls"`
+}
+
+// SLSConfig 阿里云日志服务配置，开启后日志同时投递到SLS
+type SLSConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// 服务入口，如 cn-hangzhou.log.aliyuncs.com；带协议的地址（如 http://127.0.0.1:9000）原样使用，便于对接本地模拟服务
+	Endpoint string `mapstructure:"endpoint"`
+	Project  string `mapstructure:"project"`
+	Logstore string `mapstructure:"logstore"`
+	Topic    string `mapstructure:"topic"`
+	// 投递的最低日志级别，本地日志级别更高时以本地为准
+	Level string `mapstructure:"level"`
+	// 单批最多条数与最长等待时间（毫秒）
+	BatchSize     int `mapstructure:"batch_size"`
+	FlushInterval int `mapstructure:"flush_interval"`
+	// 待投递队列长度，队列满时丢弃新日志，不阻塞业务
+	QueueSize int `mapstructure:"queue_size"`
+	// 投递失败的重试次数与单次请求超时（秒）
+	MaxRetries int `mapstructure:"max_retries"`
+	Timeout    int `mapstructure:"timeout"`
+}
+
+// FeishuConfig 飞书机器人配置
 type FeishuConfig struct {
 	WebhookURL string `mapstructure:"webhook_url"`
+	// 机器人安全设置中的签名密钥，为空时不签名
+	Secret string `mapstructure:"secret"`
+}
+
+// AlertConfig 告警配置
+type AlertConfig struct {
+	// 各级别（info, warning, critical）告警发送到的通道: feishu, dingtalk, wecom, slack, email；未配置的通道会被跳过
+	Routes map[string][]string `mapstructure:"routes"`
+	// 相同告警（级别和标题相同）的静默时间（秒），避免panic风暴刷屏，0表示不静默
+	SilenceWindow int `mapstructure:"silence_window"`
+	// 待发送队列长度，队列满时丢弃新告警，不阻塞业务
+	QueueSize int `mapstructure:"queue_size"`
+	// 单个通道的发送超时（秒）
+	Timeout int `mapstructure:"timeout"`
+
+	Feishu   FeishuConfig     `mapstructure:"feishu"`
+	DingTalk DingTalkConfig   `mapstructure:"dingtalk"`
+	WeCom    WebhookConfig    `mapstructure:"wecom"`
+	Slack    WebhookConfig    `mapstructure:"slack"`
+	Email    AlertEmailConfig `mapstructure:"email"`
+}
+
+// DingTalkConfig 钉钉机器人配置
+type DingTalkConfig struct {
+	WebhookURL string `mapstructure:"webhook_url"`
+	// 机器人安全设置中的加签密钥，为空时不签名
+	Secret string `mapstructure:"secret"`
+}
+
+// WebhookConfig 只需webhook地址的机器人配置（企业微信、Slack）
+type WebhookConfig struct {
+	WebhookURL string `mapstructure:"webhook_url"`
+}
+
+// AlertEmailConfig 告警邮件配置，通过 email 配置的SMTP服务器发送
+type AlertEmailConfig struct {
+	To []string `mapstructure:"to"`
 }
 
 // MQConfig 消息队列配置
@@ -102,16 +233,156 @@ type MQConfig struct {
 	Username string `mapstructure:"username"`
 	Password string `mapstructure:"password"`
 	Vhost    string `mapstructure:"vhost"`
+	// 发布通道池大小与发布确认超时（秒）
+	ChannelPoolSize int `mapstructure:"channel_pool_size"`
+	ConfirmTimeout  int `mapstructure:"confirm_timeout"`
+	// 延迟消息模式: plugin（RabbitMQ延迟插件）, redis（Redis有序集合调度）
+	DelayMode string `mapstructure:"delay_mode"`
+	// 类型化订阅的死信配置方式: arguments（声明队列时带 x-dead-letter-* 参数）, policy（由RabbitMQ策略配置）
+	DeadLetterMode string `mapstructure:"dead_letter_mode"`
+}
+
+// OutboxConfig 事务发件箱配置
+type OutboxConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`
+	Exchange     string `mapstructure:"exchange"`
+	PollInterval int    `mapstructure:"poll_interval"`
+	BatchSize    int    `mapstructure:"batch_size"`
+	MaxAttempts  int    `mapstructure:"max_attempts"`
+	Retention    int    `mapstructure:"retention"`
+}
+
+// EventConfig 领域事件配置
+type EventConfig struct {
+	ForwardToMQ bool   `mapstructure:"forward_to_mq"`
+	Exchange    string `mapstructure:"exchange"`
+}
+
+// JobConfig 后台任务配置
+type JobConfig struct {
+	Enabled      bool `mapstructure:"enabled"`
+	Concurrency  int  `mapstructure:"concurrency"`
+	PollInterval int  `mapstructure:"poll_interval"`
+	Timeout      int  `mapstructure:"timeout"`
+	Lease        int  `mapstructure:"lease"`
+	RetryBackoff int  `mapstructure:"retry_backoff"`
+	// 已结束任务的保留时间（秒）
+	Retention int `mapstructure:"retention"`
+}
+
+// SchedulerConfig 定时任务配置
+type SchedulerConfig struct {
+	Enabled bool                           `mapstructure:"enabled"`
+	Tasks   map[string]SchedulerTaskConfig `mapstructure:"tasks"`
+}
+
+// SchedulerTaskConfig 单个定时任务的配置覆盖
+type SchedulerTaskConfig struct {
+	Spec         string `mapstructure:"spec"`
+	Disabled     bool   `mapstructure:"disabled"`
+	MissedPolicy string `mapstructure:"missed_policy"`
+}
+
+// SecurityConfig 安全配置
+type SecurityConfig struct {
+	// 敏感数据加密密钥，长度为16、24或32字节
+	EncryptKey string     `mapstructure:"encrypt_key"`
+	CORS       CORSConfig `mapstructure:"cors"`
+}
+
+// CORSConfig 跨域配置
+type CORSConfig struct {
+	// 允许的来源，支持 "*"（任意来源）和 "https://*.example.com"（任意子域名）
+	AllowOrigins  []string `mapstructure:"allow_origins"`
+	AllowMethods  []string `mapstructure:"allow_methods"`
+	AllowHeaders  []string `mapstructure:"allow_headers"`
+	ExposeHeaders []string `mapstructure:"expose_headers"`
+	// 预检请求缓存时间（秒）
+	MaxAge int `mapstructure:"max_age"`
+	// 是否允许携带Cookie等凭证，不能与 "*" 同时使用
+	AllowCredentials bool `mapstructure:"allow_credentials"`
+}
+
+// MetricsConfig Prometheus指标配置
+type MetricsConfig struct {
+	// HTTP请求耗时分桶（秒）
+	DurationBuckets []float64 `mapstructure:"duration_buckets"`
+	// HTTP请求/响应大小分桶（字节）
+	SizeBuckets []float64 `mapstructure:"size_buckets"`
+}
+
+// AdminConfig 运维端点配置：Prometheus指标、pprof、Swagger、健康检查详情和日志级别
+type AdminConfig struct {
+	// 独立监听地址，如 127.0.0.1:9091；为空时挂在主端口，此时必须配置basic认证或IP白名单
+	Addr string `mapstructure:"addr"`
+	// basic认证，用户名为空时不启用
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	// 允许访问的IP或CIDR，为空时不限制；按TCP连接的对端地址判断，不信任 X-Forwarded-For
+	AllowIPs []string `mapstructure:"allow_ips"`
+	// 是否开放pprof和Swagger文档
+	Pprof   bool `mapstructure:"pprof"`
+	Swagger bool `mapstructure:"swagger"`
+}
+
+// PerformanceConfig 性能配置
+type PerformanceConfig struct {
+	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+	Cache     CacheConfig     `mapstructure:"cache"`
+}
+
+// RateLimitConfig 限流配置
+type RateLimitConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// 计数存储: memory（单实例）, redis（多实例共享）
+	Store string `mapstructure:"store"`
+	// 默认策略：每分钟请求数
+	Rate int `mapstructure:"rate"`
+	// 默认策略：每秒最多请求数，限制瞬时突发，0表示不限制
+	Burst int `mapstructure:"burst"`
+	// 默认策略：限流维度 user, api_key, ip
+	KeyBy string `mapstructure:"key_by"`
+	// 传递API Key的请求头
+	APIKeyHeader string `mapstructure:"api_key_header"`
+	// 已分配的API Key，只有其中的key才按key单独计数，未知的key按IP计数
+	APIKeys []string `mapstructure:"api_keys"`
+	// 不限流的IP或网段、用户名、API Key
+	ExemptIPs     []string `mapstructure:"exempt_ips"`
+	ExemptUsers   []string `mapstructure:"exempt_users"`
+	ExemptAPIKeys []string `mapstructure:"exempt_api_keys"`
+	// 按路由前缀配置的策略，最长前缀优先，未匹配时使用默认策略
+	Policies map[string]RateLimitPolicy `mapstructure:"policies"`
+}
+
+// RateLimitPolicy 路由组限流策略
+type RateLimitPolicy struct {
+	Prefix   string `mapstructure:"prefix"`
+	Rate     int    `mapstructure:"rate"`
+	Burst    int    `mapstructure:"burst"`
+	KeyBy    string `mapstructure:"key_by"`
+	Disabled bool   `mapstructure:"disabled"`
+}
+
+// CacheConfig 缓存配置
+type CacheConfig struct {
+	// 默认缓存时间（秒）
+	DefaultTTL int    `mapstructure:"default_ttl"`
+	MaxMemory  string `mapstructure:"max_memory"`
+}
+
+// HealthConfig 健康检查配置
+type HealthConfig struct {
+	// 检查结果缓存时间（秒），避免探针频繁访问依赖
+	CacheTTL int `mapstructure:"cache_ttl"`
+	// 单项检查超时（秒）
+	Timeout int `mapstructure:"timeout"`
 }
 
-var cfg *Config
+var cfg atomic.Pointer[Config]
 
 // Init 初始化配置
 func Init() *Config {
-	viper.SetConfigName("app")
 	viper.SetConfigType("yaml")
-	viper.AddConfigPath("./configs")
-	viper.AddConfigPath(".")
 
 	// 支持环境变量
 	viper.AutomaticEnv()
@@ -120,25 +391,60 @@ func Init() *Config {
 	// 设置默认值
 	setDefaults()
 
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			// 配置文件未找到，使用默认配置
-		} else {
-			panic(err)
-		}
+	// 配置文件未找到时使用默认配置
+	if err := readInConfig(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 
-	cfg = &Config{}
-	if err := viper.Unmarshal(cfg); err != nil {
-		panic(err)
+	// 校验失败时一次性输出全部问题后退出
+	loaded, err := load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 
-	return cfg
+	cfg.Store(loaded)
+	return loaded
+}
+
+// load 从viper解析并校验配置
+func load() (*Config, error) {
+	loaded := &Config{}
+	var metadata mapstructure.Metadata
+	if err := viper.Unmarshal(loaded, func(dc *mapstructure.DecoderConfig) {
+		dc.Metadata = &metadata
+	}); err != nil {
+		return nil, err
+	}
+
+	// 兼容旧配置 feishu.webhook_url
+	if loaded.Alert.Feishu.WebhookURL == "" {
+		loaded.Alert.Feishu = loaded.Feishu
+	}
+
+	var problems []string
+	for _, key := range unknownKeys(metadata.Unused) {
+		problems = append(problems, fmt.Sprintf("%s: 未知的配置项（拼写错误？）", key))
+	}
+	if err := loaded.Validate(); err != nil {
+		problems = append(problems, err.(*ValidationError).Problems...)
+	}
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	return loaded, nil
 }
 
 // Get 获取配置
 func Get() *Config {
-	return cfg
+	return cfg.Load()
+}
+
+// FeatureEnabled 功能开关是否开启
+func FeatureEnabled(name string) bool {
+	return Get().Features[name]
 }
 
 // setDefaults 设置默认配置
@@ -147,6 +453,10 @@ func setDefaults() {
 	viper.SetDefault("app.name", "ocean-marketing")
 	viper.SetDefault("app.port", ":8080")
 	viper.SetDefault("app.mode", "debug")
+	viper.SetDefault("app.admin_users", []string{"admin"})
+	viper.SetDefault("app.trusted_proxies", []string{})
+	viper.SetDefault("app.shutdown_timeout", 30)
+	viper.SetDefault("app.shutdown_delay", 0)
 
 	// Database默认配置
 	viper.SetDefault("database.driver", "mysql")
@@ -159,6 +469,7 @@ func setDefaults() {
 	viper.SetDefault("database.max_idle_conns", 10)
 	viper.SetDefault("database.max_open_conns", 100)
 	viper.SetDefault("database.conn_max_lifetime", 3600)
+	viper.SetDefault("database.slow_threshold", 200)
 
 	// Redis默认配置
 	viper.SetDefault("redis.host", "localhost")
@@ -174,6 +485,22 @@ func setDefaults() {
 	viper.SetDefault("log.max_age", 30)
 	viper.SetDefault("log.max_backups", 10)
 	viper.SetDefault("log.compress", true)
+	viper.SetDefault("log.http.body_mode", "errors")
+	viper.SetDefault("log.http.sample_rate", 0.1)
+	viper.SetDefault("log.http.max_body_size", 4096)
+	viper.SetDefault("log.http.content_types", []string{"application/json", "application/x-www-form-urlencoded", "application/xml", "text/"})
+	viper.SetDefault("log.http.redact_fields", []string{"password", "old_password", "new_password", "token", "access_token", "refresh_token", "secret", "api_key"})
+	viper.SetDefault("log.http.log_headers", false)
+	viper.SetDefault("log.http.redact_headers", []string{"Authorization", "Cookie", "Set-Cookie", "X-API-Key"})
+
+	// Aliyun默认配置
+	viper.SetDefault("aliyun.sls.enabled", false)
+	viper.SetDefault("aliyun.sls.level", "info")
+	viper.SetDefault("aliyun.sls.batch_size", 512)
+	viper.SetDefault("aliyun.sls.flush_interval", 1000)
+	viper.SetDefault("aliyun.sls.queue_size", 8192)
+	viper.SetDefault("aliyun.sls.max_retries", 3)
+	viper.SetDefault("aliyun.sls.timeout", 5)
 
 	// JWT默认配置
 	viper.SetDefault("jwt.secret", "ocean-marketing-secret")
@@ -185,10 +512,14 @@ func setDefaults() {
 	viper.SetDefault("email.port", 587)
 
 	// Tracer默认配置
+	viper.SetDefault("tracer.enabled", true)
 	viper.SetDefault("tracer.service_name", "ocean-marketing")
-	viper.SetDefault("tracer.agent_host", "localhost")
-	viper.SetDefault("tracer.agent_port", 6831)
+	viper.SetDefault("tracer.exporter", "otlp_grpc")
+	viper.SetDefault("tracer.endpoint", "localhost:4317")
+	viper.SetDefault("tracer.insecure", true)
 	viper.SetDefault("tracer.sample_rate", 1.0)
+	viper.SetDefault("tracer.parent_based", true)
+	viper.SetDefault("tracer.timeout", 10)
 
 	// MQ默认配置
 	viper.SetDefault("mq.driver", "rabbitmq")
@@ -197,4 +528,84 @@ func setDefaults() {
 	viper.SetDefault("mq.username", "guest")
 	viper.SetDefault("mq.password", "guest")
 	viper.SetDefault("mq.vhost", "/")
+	viper.SetDefault("mq.channel_pool_size", 10)
+	viper.SetDefault("mq.confirm_timeout", 5)
+	viper.SetDefault("mq.delay_mode", "redis")
+	viper.SetDefault("mq.dead_letter_mode", "arguments")
+
+	// Outbox默认配置
+	viper.SetDefault("outbox.enabled", false)
+	viper.SetDefault("outbox.exchange", "ocean-marketing.events")
+	viper.SetDefault("outbox.poll_interval", 1)
+	viper.SetDefault("outbox.batch_size", 100)
+	viper.SetDefault("outbox.max_attempts", 10)
+	viper.SetDefault("outbox.retention", 604800)
+
+	// Event默认配置
+	viper.SetDefault("event.forward_to_mq", false)
+	viper.SetDefault("event.exchange", "ocean-marketing.events")
+
+	// Job默认配置
+	viper.SetDefault("job.enabled", false)
+	viper.SetDefault("job.concurrency", 4)
+	viper.SetDefault("job.poll_interval", 1)
+	viper.SetDefault("job.timeout", 600)
+	viper.SetDefault("job.lease", 60)
+	viper.SetDefault("job.retry_backoff", 10)
+	viper.SetDefault("job.retention", 604800)
+
+	// Scheduler默认配置
+	viper.SetDefault("scheduler.enabled", false)
+
+	// Security默认配置，默认不允许跨域
+	viper.SetDefault("security.cors.allow_origins", []string{})
+	viper.SetDefault("security.cors.allow_methods", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"})
+	viper.SetDefault("security.cors.allow_headers", []string{"Authorization", "Content-Type", "X-Requested-With", "X-Request-ID"})
+	viper.SetDefault("security.cors.expose_headers", []string{"X-Request-ID"})
+	viper.SetDefault("security.cors.max_age", 86400)
+	viper.SetDefault("security.cors.allow_credentials", false)
+
+	// Health默认配置
+	viper.SetDefault("health.cache_ttl", 2)
+	viper.SetDefault("health.timeout", 3)
+
+	// Metrics默认配置
+	viper.SetDefault("metrics.duration_buckets", []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10})
+	viper.SetDefault("metrics.size_buckets", []float64{100, 1000, 10000, 100000, 1e+06, 1e+07})
+
+	// Alert默认配置，各通道配置了地址（或收件人）后才会发送
+	viper.SetDefault("alert.routes", map[string][]string{
+		"critical": {"feishu", "dingtalk", "wecom", "slack", "email"},
+		"warning":  {"feishu", "dingtalk", "wecom", "slack"},
+		"info":     {},
+	})
+	viper.SetDefault("alert.silence_window", 60)
+	viper.SetDefault("alert.queue_size", 100)
+	viper.SetDefault("alert.timeout", 10)
+	viper.SetDefault("alert.feishu.webhook_url", "")
+	viper.SetDefault("alert.feishu.secret", "")
+	viper.SetDefault("alert.dingtalk.webhook_url", "")
+	viper.SetDefault("alert.dingtalk.secret", "")
+	viper.SetDefault("alert.wecom.webhook_url", "")
+	viper.SetDefault("alert.slack.webhook_url", "")
+	viper.SetDefault("alert.email.to", []string{})
+
+	// Admin默认配置，默认只在本机监听
+	viper.SetDefault("admin.addr", "127.0.0.1:9091")
+	viper.SetDefault("admin.username", "")
+	viper.SetDefault("admin.password", "")
+	viper.SetDefault("admin.allow_ips", []string{})
+	viper.SetDefault("admin.pprof", true)
+	viper.SetDefault("admin.swagger", true)
+
+	// Performance默认配置
+	viper.SetDefault("performance.rate_limit.enabled", true)
+	viper.SetDefault("performance.rate_limit.rate", 100)
+	viper.SetDefault("performance.rate_limit.burst", 0)
+	viper.SetDefault("performance.rate_limit.store", "memory")
+	viper.SetDefault("performance.rate_limit.key_by", "ip")
+	viper.SetDefault("performance.rate_limit.api_key_header", "X-API-Key")
+	viper.SetDefault("performance.rate_limit.api_keys", []string{})
+	viper.SetDefault("performance.cache.default_ttl", 3600)
+	viper.SetDefault("performance.cache.max_memory", "128mb")
 }
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"io"
+	"strings"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// redactedValue 脱敏后的占位值
+const redactedValue = "******"
+
+// sensitiveKeys 配置项名称包含这些片段时视为敏感信息
+var sensitiveKeys = []string{
+	"password",
+	"secret",
+	"token",
+	"encrypt_key",
+	"access_key",
+	"webhook_url",
+	"api_key",
+	"authorization",
+}
+
+// sensitiveExactKeys 名称完全相同时视为敏感信息，避免误伤 log_headers 等开关
+var sensitiveExactKeys = []string{
+	"headers",
+}
+
+// Print 以YAML输出合并默认值、配置文件、环境变量和密钥文件后的实际配置，redact为true时隐藏敏感信息
+func Print(w io.Writer, redact bool) error {
+	settings := viper.AllSettings()
+	if redact {
+		redactMap(settings)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(settings); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// redactMap 递归隐藏敏感配置项
+func redactMap(settings map[string]interface{}) {
+	for key, value := range settings {
+		if isSensitive(key) {
+			settings[key] = redactValue(value)
+			continue
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			redactMap(nested)
+		}
+	}
+}
+
+// redactValue 隐藏敏感配置项的值：map（如 tracer.headers）保留键名隐藏全部值，空值保持原样便于确认是否已配置
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range v {
+			v[key] = redactValue(nested)
+		}
+		return v
+	case []interface{}:
+		if len(v) == 0 {
+			return v
+		}
+	case []string:
+		if len(v) == 0 {
+			return v
+		}
+	case string:
+		if v == "" {
+			return v
+		}
+	case nil:
+		return v
+	}
+	return redactedValue
+}
+
+// isSensitive 判断配置项是否为敏感信息
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveExactKeys {
+		if key == s {
+			return true
+		}
+	}
+	for _, s := range sensitiveKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// 配置文件查找目录，按顺序优先
+var configPaths = []string{"./configs", "."}
+
+// envPattern 匹配 ${VAR} 和 ${VAR:-default}
+var envPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// Env 当前环境，由环境变量 APP_ENV 指定，用于加载 app.{env}.yaml 覆盖配置
+func Env() string {
+	return strings.TrimSpace(os.Getenv("APP_ENV"))
+}
+
+// Files 返回当前生效的配置文件，基础配置在前，环境覆盖配置在后
+func Files() []string {
+	var files []string
+	if base := findFile("app.yaml"); base != "" {
+		files = append(files, base)
+	}
+	if env := Env(); env != "" {
+		if overlay := findFile("app." + env + ".yaml"); overlay != "" {
+			files = append(files, overlay)
+		}
+	}
+	return files
+}
+
+// findFile 在配置目录中查找文件
+func findFile(name string) string {
+	for _, dir := range configPaths {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// readInConfig 依次读取基础配置和环境覆盖配置，展开 ${ENV} 后合并，再应用 *_FILE 密钥文件
+func readInConfig() error {
+	// 清空上次读取的文件内容，默认值和环境变量不受影响
+	if err := viper.ReadConfig(bytes.NewReader(nil)); err != nil {
+		return err
+	}
+
+	for _, file := range Files() {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("读取配置文件 %s 失败: %w", file, err)
+		}
+
+		settings := make(map[string]interface{})
+		if err := yaml.Unmarshal(content, &settings); err != nil {
+			return fmt.Errorf("解析配置文件 %s 失败: %w", file, err)
+		}
+
+		// 只展开配置值，注释中的 ${...} 不受影响
+		var missing []string
+		interpolate(settings, &missing)
+		if len(missing) > 0 {
+			return fmt.Errorf("%s: 未设置环境变量: %s", file, strings.Join(missing, ", "))
+		}
+
+		if err := viper.MergeConfigMap(settings); err != nil {
+			return fmt.Errorf("合并配置文件 %s 失败: %w", file, err)
+		}
+	}
+
+	return applySecretFiles()
+}
+
+// interpolate 递归展开配置值中的 ${VAR}，未设置且无默认值的变量记入 missing
+func interpolate(value interface{}, missing *[]string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = interpolate(item, missing)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = interpolate(item, missing)
+		}
+		return v
+	case string:
+		return envPattern.ReplaceAllStringFunc(v, func(match string) string {
+			groups := envPattern.FindStringSubmatch(match)
+			if env, ok := os.LookupEnv(groups[1]); ok {
+				return env
+			}
+			if groups[2] != "" {
+				return groups[3]
+			}
+			*missing = append(*missing, groups[1])
+			return match
+		})
+	}
+	return value
+}
+
+// applySecretFiles 配置项 a.b 对应的环境变量 A_B_FILE 指向文件时，以文件内容作为该配置项的值
+func applySecretFiles() error {
+	for _, key := range viper.AllKeys() {
+		name := strings.ToUpper(strings.ReplaceAll(key, ".", "_")) + "_FILE"
+		path := os.Getenv(name)
+		if path == "" {
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("读取 %s 指定的密钥文件失败: %w", name, err)
+		}
+		viper.Set(key, strings.TrimRight(string(content), "\r\n"))
+	}
+	return nil
+}
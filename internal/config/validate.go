@@ -0,0 +1,418 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 示例中的加密密钥，release模式下禁止使用
+const exampleEncryptKey = "your-32-char-encrypt-key-here123"
+
+// 默认/示例中的JWT密钥，release模式下禁止使用
+var insecureJWTSecrets = []string{
+	"ocean-marketing-secret",
+	"your-jwt-secret-key-change-in-production",
+}
+
+// reservedSections 已在 app.yaml.example 中说明但尚未被代码读取的配置段，不视为未知配置
+var reservedSections = []string{
+	"aliyun.oss",
+	"aliyun.cms",
+}
+
+// ValidationError 配置校验错误，包含发现的全部问题
+type ValidationError struct {
+	Problems []string
+}
+
+// Error 实现error接口
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("配置校验失败，共%d个问题:", len(e.Problems)))
+	for _, problem := range e.Problems {
+		b.WriteString("\n  - ")
+		b.WriteString(problem)
+	}
+	return b.String()
+}
+
+// validator 收集校验问题
+type validator struct {
+	problems []string
+}
+
+func (v *validator) addf(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) required(key, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.addf("%s: 不能为空", key)
+	}
+}
+
+func (v *validator) min(key string, value, min int) {
+	if value < min {
+		v.addf("%s: 不能小于%d，当前为%d", key, min, value)
+	}
+}
+
+func (v *validator) port(key string, value int) {
+	if value < 1 || value > 65535 {
+		v.addf("%s: 端口必须在1-65535之间，当前为%d", key, value)
+	}
+}
+
+func (v *validator) oneOf(key, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.addf("%s: 取值必须是 %s 之一，当前为%q", key, strings.Join(allowed, ", "), value)
+}
+
+func (v *validator) listenAddr(key, value string) {
+	_, portStr, err := net.SplitHostPort(value)
+	if err != nil {
+		v.addf("%s: 监听地址格式应为 host:port 或 :port，当前为%q", key, value)
+		return
+	}
+	if port, err := strconv.Atoi(portStr); err != nil || port < 1 || port > 65535 {
+		v.addf("%s: 端口必须在1-65535之间，当前为%q", key, portStr)
+	}
+}
+
+func (v *validator) hostPort(key, value string) {
+	host, portStr, err := net.SplitHostPort(value)
+	if err != nil || host == "" {
+		v.addf("%s: 地址格式应为 host:port，当前为%q", key, value)
+		return
+	}
+	if port, err := strconv.Atoi(portStr); err != nil || port < 1 || port > 65535 {
+		v.addf("%s: 端口必须在1-65535之间，当前为%q", key, portStr)
+	}
+}
+
+func (v *validator) httpURL(key, value string) {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.addf("%s: 必须是http(s)地址，当前为%q", key, value)
+	}
+}
+
+// buckets 校验直方图分桶，不能为空且必须严格递增
+func (v *validator) buckets(key string, values []float64) {
+	if len(values) == 0 {
+		v.addf("%s: 不能为空", key)
+		return
+	}
+	for i := 1; i < len(values); i++ {
+		if values[i] <= values[i-1] {
+			v.addf("%s: 必须严格递增，当前为%v", key, values)
+			return
+		}
+	}
+}
+
+// origin 校验CORS来源，格式为 scheme://host[:port]，host 可以以 "*." 开头表示任意子域名
+func (v *validator) origin(key, value string) {
+	u, err := url.Parse(strings.Replace(value, "://*.", "://wildcard.", 1))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		(u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		v.addf("%s: 格式应为 scheme://host[:port] 或 scheme://*.domain，当前为%q", key, value)
+	}
+}
+
+// Validate 校验配置，返回包含全部问题的 *ValidationError
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	// App
+	v.required("app.name", c.App.Name)
+	v.listenAddr("app.port", c.App.Port)
+	v.oneOf("app.mode", c.App.Mode, "debug", "release", "test")
+	v.min("app.shutdown_timeout", c.App.ShutdownTimeout, 1)
+	v.min("app.shutdown_delay", c.App.ShutdownDelay, 0)
+	for i, ip := range c.App.TrustedProxies {
+		if net.ParseIP(ip) == nil {
+			if _, _, err := net.ParseCIDR(ip); err != nil {
+				v.addf("app.trusted_proxies[%d]: 必须是IP或CIDR网段，当前为%q", i, ip)
+			}
+		}
+	}
+
+	// Database
+	v.oneOf("database.driver", c.Database.Driver, "mysql", "postgres")
+	v.required("database.host", c.Database.Host)
+	v.port("database.port", c.Database.Port)
+	v.required("database.database", c.Database.Database)
+	v.required("database.username", c.Database.Username)
+	v.min("database.max_idle_conns", c.Database.MaxIdleConns, 0)
+	v.min("database.max_open_conns", c.Database.MaxOpenConns, 1)
+	if c.Database.MaxIdleConns > c.Database.MaxOpenConns && c.Database.MaxOpenConns > 0 {
+		v.addf("database.max_idle_conns: 不能大于 max_open_conns（%d > %d）", c.Database.MaxIdleConns, c.Database.MaxOpenConns)
+	}
+	v.min("database.conn_max_lifetime", c.Database.ConnMaxLifetime, 0)
+	v.min("database.slow_threshold", c.Database.SlowThreshold, 0)
+
+	// Redis
+	v.required("redis.host", c.Redis.Host)
+	v.port("redis.port", c.Redis.Port)
+	if c.Redis.DB < 0 || c.Redis.DB > 15 {
+		v.addf("redis.db: 必须在0-15之间，当前为%d", c.Redis.DB)
+	}
+	v.min("redis.pool_size", c.Redis.PoolSize, 0)
+	v.min("redis.min_idle_conns", c.Redis.MinIdleConns, 0)
+
+	// Log
+	v.oneOf("log.level", c.Log.Level, "debug", "info", "warn", "error", "fatal")
+	v.oneOf("log.format", c.Log.Format, "json", "console")
+	for module, level := range c.Log.Modules {
+		v.oneOf("log.modules."+module, level, "debug", "info", "warn", "error", "fatal")
+	}
+	v.required("log.output_path", c.Log.OutputPath)
+	v.min("log.max_size", c.Log.MaxSize, 1)
+	v.oneOf("log.http.body_mode", c.Log.HTTP.BodyMode, "off", "errors", "sampled", "all")
+	if c.Log.HTTP.SampleRate < 0 || c.Log.HTTP.SampleRate > 1 {
+		v.addf("log.http.sample_rate: 必须在0-1之间，当前为%v", c.Log.HTTP.SampleRate)
+	}
+	v.min("log.http.max_body_size", c.Log.HTTP.MaxBodySize, 0)
+	for i, field := range c.Log.HTTP.RedactFields {
+		if field == "" || strings.HasPrefix(field, ".") || strings.HasSuffix(field, ".") || strings.Contains(field, "..") {
+			v.addf("log.http.redact_fields[%d]: 无效的字段路径 %q", i, field)
+		}
+	}
+
+	// JWT
+	v.required("jwt.secret", c.JWT.Secret)
+	v.min("jwt.expire_time", c.JWT.ExpireTime, 1)
+	if c.App.Mode == "release" {
+		for _, secret := range insecureJWTSecrets {
+			if c.JWT.Secret == secret {
+				v.addf("jwt.secret: release模式下不能使用默认密钥，请通过配置或环境变量 JWT_SECRET 设置")
+			}
+		}
+		if c.JWT.Secret != "" && len(c.JWT.Secret) < 32 {
+			v.addf("jwt.secret: release模式下长度不能少于32个字符")
+		}
+	}
+
+	// Email
+	if c.Email.Host != "" {
+		v.port("email.port", c.Email.Port)
+	}
+
+	// Tracer
+	if c.Tracer.Enabled {
+		v.required("tracer.service_name", c.Tracer.ServiceName)
+		v.oneOf("tracer.exporter", c.Tracer.Exporter, "otlp_grpc", "otlp_http", "stdout", "memory", "none")
+		if c.Tracer.Exporter == "otlp_grpc" || c.Tracer.Exporter == "otlp_http" {
+			v.hostPort("tracer.endpoint", c.Tracer.Endpoint)
+		}
+		if c.Tracer.SampleRate < 0 || c.Tracer.SampleRate > 1 {
+			v.addf("tracer.sample_rate: 必须在0-1之间，当前为%v", c.Tracer.SampleRate)
+		}
+		v.min("tracer.timeout", c.Tracer.Timeout, 1)
+	}
+
+	// Alert
+	for severity, channels := range c.Alert.Routes {
+		v.oneOf("alert.routes", severity, "info", "warning", "critical")
+		for _, channel := range channels {
+			v.oneOf("alert.routes."+severity, channel, "feishu", "dingtalk", "wecom", "slack", "email")
+		}
+	}
+	v.min("alert.silence_window", c.Alert.SilenceWindow, 0)
+	v.min("alert.queue_size", c.Alert.QueueSize, 1)
+	v.min("alert.timeout", c.Alert.Timeout, 1)
+	if c.Alert.Feishu.WebhookURL != "" {
+		v.httpURL("alert.feishu.webhook_url", c.Alert.Feishu.WebhookURL)
+	}
+	if c.Alert.DingTalk.WebhookURL != "" {
+		v.httpURL("alert.dingtalk.webhook_url", c.Alert.DingTalk.WebhookURL)
+	}
+	if c.Alert.WeCom.WebhookURL != "" {
+		v.httpURL("alert.wecom.webhook_url", c.Alert.WeCom.WebhookURL)
+	}
+	if c.Alert.Slack.WebhookURL != "" {
+		v.httpURL("alert.slack.webhook_url", c.Alert.Slack.WebhookURL)
+	}
+	if len(c.Alert.Email.To) > 0 {
+		v.required("email.host", c.Email.Host)
+	}
+
+	// MQ
+	v.oneOf("mq.driver", c.MQ.Driver, "rabbitmq")
+	v.port("mq.port", c.MQ.Port)
+	v.min("mq.channel_pool_size", c.MQ.ChannelPoolSize, 1)
+	v.min("mq.confirm_timeout", c.MQ.ConfirmTimeout, 1)
+	v.oneOf("mq.delay_mode", c.MQ.DelayMode, "plugin", "redis")
+	v.oneOf("mq.dead_letter_mode", c.MQ.DeadLetterMode, "arguments", "policy")
+
+	// Outbox
+	if c.Outbox.Enabled {
+		v.required("outbox.exchange", c.Outbox.Exchange)
+		v.min("outbox.poll_interval", c.Outbox.PollInterval, 1)
+		v.min("outbox.batch_size", c.Outbox.BatchSize, 1)
+		v.min("outbox.max_attempts", c.Outbox.MaxAttempts, 1)
+	}
+
+	// Event
+	if c.Event.ForwardToMQ {
+		v.required("event.exchange", c.Event.Exchange)
+	}
+
+	// Job
+	if c.Job.Enabled {
+		v.min("job.concurrency", c.Job.Concurrency, 1)
+		v.min("job.poll_interval", c.Job.PollInterval, 1)
+		v.min("job.timeout", c.Job.Timeout, 1)
+		v.min("job.lease", c.Job.Lease, 3)
+		v.min("job.retry_backoff", c.Job.RetryBackoff, 1)
+	}
+
+	// Scheduler
+	names := make([]string, 0, len(c.Scheduler.Tasks))
+	for name := range c.Scheduler.Tasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if policy := c.Scheduler.Tasks[name].MissedPolicy; policy != "" {
+			v.oneOf("scheduler.tasks."+name+".missed_policy", policy, "skip", "run_once")
+		}
+	}
+
+	// Security
+	if key := c.Security.EncryptKey; key != "" {
+		if n := len(key); n != 16 && n != 24 && n != 32 {
+			v.addf("security.encrypt_key: 长度必须为16、24或32个字符，当前为%d", n)
+		}
+		if c.App.Mode == "release" && key == exampleEncryptKey {
+			v.addf("security.encrypt_key: release模式下不能使用示例密钥")
+		}
+	}
+	for i, origin := range c.Security.CORS.AllowOrigins {
+		key := fmt.Sprintf("security.cors.allow_origins[%d]", i)
+		if origin == "*" {
+			if c.Security.CORS.AllowCredentials {
+				v.addf("%s: allow_credentials 为 true 时不能允许任意来源 \"*\"", key)
+			}
+			continue
+		}
+		v.origin(key, origin)
+	}
+	v.min("security.cors.max_age", c.Security.CORS.MaxAge, 0)
+
+	// Performance
+	rl := c.Performance.RateLimit
+	if rl.Enabled {
+		v.oneOf("performance.rate_limit.store", rl.Store, "memory", "redis")
+		v.min("performance.rate_limit.rate", rl.Rate, 1)
+		v.min("performance.rate_limit.burst", rl.Burst, 0)
+		v.oneOf("performance.rate_limit.key_by", rl.KeyBy, "user", "api_key", "ip")
+		v.required("performance.rate_limit.api_key_header", rl.APIKeyHeader)
+		for i, ip := range rl.ExemptIPs {
+			if net.ParseIP(ip) == nil {
+				if _, _, err := net.ParseCIDR(ip); err != nil {
+					v.addf("performance.rate_limit.exempt_ips[%d]: 必须是IP或CIDR网段，当前为%q", i, ip)
+				}
+			}
+		}
+
+		policies := make([]string, 0, len(rl.Policies))
+		for name := range rl.Policies {
+			policies = append(policies, name)
+		}
+		sort.Strings(policies)
+		for _, name := range policies {
+			policy := rl.Policies[name]
+			key := "performance.rate_limit.policies." + name
+			if !strings.HasPrefix(policy.Prefix, "/") {
+				v.addf("%s.prefix: 必须以 / 开头，当前为%q", key, policy.Prefix)
+			}
+			if policy.Disabled {
+				continue
+			}
+			v.min(key+".rate", policy.Rate, 1)
+			v.min(key+".burst", policy.Burst, 0)
+			if policy.KeyBy != "" {
+				v.oneOf(key+".key_by", policy.KeyBy, "user", "api_key", "ip")
+			}
+		}
+	}
+	v.min("performance.cache.default_ttl", c.Performance.Cache.DefaultTTL, 0)
+
+	// Health
+	v.min("health.cache_ttl", c.Health.CacheTTL, 0)
+	v.min("health.timeout", c.Health.Timeout, 1)
+
+	// Metrics
+	v.buckets("metrics.duration_buckets", c.Metrics.DurationBuckets)
+	v.buckets("metrics.size_buckets", c.Metrics.SizeBuckets)
+
+	// Admin
+	if c.Admin.Addr != "" {
+		v.listenAddr("admin.addr", c.Admin.Addr)
+		if c.Admin.Addr == c.App.Port {
+			v.addf("admin.addr: 不能与 app.port 相同，挂在主端口时请留空")
+		}
+	} else if c.Admin.Username == "" && len(c.Admin.AllowIPs) == 0 {
+		v.addf("admin: 运维端点挂在主端口（admin.addr 为空）时必须配置 username/password 或 allow_ips")
+	}
+	if c.Admin.Username != "" {
+		v.required("admin.password", c.Admin.Password)
+	}
+	for i, ip := range c.Admin.AllowIPs {
+		if _, _, err := net.ParseCIDR(ip); err != nil && net.ParseIP(ip) == nil {
+			v.addf("admin.allow_ips[%d]: 无效的IP或CIDR %q", i, ip)
+		}
+	}
+
+	// Aliyun
+	if sls := c.Aliyun.SLS; sls.Enabled {
+		v.required("aliyun.sls.endpoint", sls.Endpoint)
+		if strings.Contains(sls.Endpoint, "://") {
+			v.httpURL("aliyun.sls.endpoint", sls.Endpoint)
+		}
+		v.required("aliyun.sls.project", sls.Project)
+		v.required("aliyun.sls.logstore", sls.Logstore)
+		v.required("aliyun.access_key_id", c.Aliyun.AccessKeyID)
+		v.required("aliyun.access_key_secret", c.Aliyun.AccessKeySecret)
+		v.oneOf("aliyun.sls.level", sls.Level, "debug", "info", "warn", "error", "fatal")
+		v.min("aliyun.sls.batch_size", sls.BatchSize, 1)
+		v.min("aliyun.sls.flush_interval", sls.FlushInterval, 10)
+		v.min("aliyun.sls.queue_size", sls.QueueSize, sls.BatchSize)
+		v.min("aliyun.sls.max_retries", sls.MaxRetries, 0)
+		v.min("aliyun.sls.timeout", sls.Timeout, 1)
+	}
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+// unknownKeys 过滤出未被任何配置字段使用的键（拼写错误等）
+func unknownKeys(unused []string) []string {
+	var keys []string
+	for _, key := range unused {
+		reserved := false
+		for _, r := range reservedSections {
+			if key == r || strings.HasPrefix(key, r+".") {
+				reserved = true
+				break
+			}
+		}
+		if !reserved {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
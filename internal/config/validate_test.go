@@ -0,0 +1,168 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// loadYAML 在默认配置上合并yaml后解析并校验
+func loadYAML(t *testing.T, content string) (*Config, error) {
+	t.Helper()
+
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	viper.SetConfigType("yaml")
+	setDefaults()
+	if err := viper.ReadConfig(strings.NewReader(content)); err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	return load()
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		// want 期望出现的问题（前缀匹配），为空表示校验通过
+		want []string
+	}{
+		{
+			name: "默认配置",
+		},
+		{
+			name: "未知配置项",
+			yaml: "app:\n  nmae: ocean\nredis:\n  hots: localhost\n",
+			want: []string{"app.nmae: 未知的配置项", "redis.hots: 未知的配置项"},
+		},
+		{
+			name: "保留的配置段不视为未知",
+			yaml: "aliyun:\n  oss:\n    bucket: assets\n  cms:\n    namespace: acs\n",
+		},
+		{
+			name: "枚举取值",
+			yaml: "app:\n  mode: prod\nmq:\n  dead_letter_mode: queue\n",
+			want: []string{"app.mode: 取值必须是", "mq.dead_letter_mode: 取值必须是"},
+		},
+		{
+			name: "可信代理",
+			yaml: "app:\n  trusted_proxies: [\"10.0.0.0/8\", \"127.0.0.1\", \"proxy\"]\n",
+			want: []string{"app.trusted_proxies[2]: 必须是IP或CIDR网段"},
+		},
+		{
+			name: "release模式禁止默认密钥",
+			yaml: "app:\n  mode: release\n",
+			want: []string{"jwt.secret: release模式下不能使用默认密钥", "jwt.secret: release模式下长度不能少于32个字符"},
+		},
+		{
+			name: "CORS来源",
+			yaml: "security:\n  cors:\n    allow_credentials: true\n    allow_origins: [\"https://*.example.com\", \"example.com\", \"*\"]\n",
+			want: []string{"security.cors.allow_origins[1]: 格式应为", "security.cors.allow_origins[2]: allow_credentials 为 true 时"},
+		},
+		{
+			name: "分桶必须递增",
+			yaml: "metrics:\n  size_buckets: [100, 10, 1000]\n",
+			want: []string{"metrics.size_buckets: 必须严格递增"},
+		},
+		{
+			name: "运维端点挂在主端口需要认证",
+			yaml: "admin:\n  addr: \"\"\n",
+			want: []string{"admin: 运维端点挂在主端口"},
+		},
+		{
+			name: "发件箱至少投递一次",
+			yaml: "outbox:\n  enabled: true\n  max_attempts: 0\n",
+			want: []string{"outbox.max_attempts: 不能小于1"},
+		},
+		{
+			name: "调度任务错过策略",
+			yaml: "scheduler:\n  tasks:\n    cleanup:\n      missed_policy: catch_up\n",
+			want: []string{"scheduler.tasks.cleanup.missed_policy: 取值必须是"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadYAML(t, tt.yaml)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("load: %v", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("load error = %v, want *ValidationError", err)
+			}
+			if len(validationErr.Problems) != len(tt.want) {
+				t.Errorf("problems = %q, want %d", validationErr.Problems, len(tt.want))
+			}
+			for _, want := range tt.want {
+				found := false
+				for _, problem := range validationErr.Problems {
+					if strings.HasPrefix(problem, want) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("problems = %q, missing %q", validationErr.Problems, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadExample(t *testing.T) {
+	content, err := os.ReadFile("../../configs/app.yaml.example")
+	if err != nil {
+		t.Fatalf("read example: %v", err)
+	}
+
+	loaded, err := loadYAML(t, string(content))
+	if err != nil {
+		t.Fatalf("app.yaml.example 应能通过校验: %v", err)
+	}
+
+	// 示例与默认值应保持一致
+	viper.Reset()
+	setDefaults()
+	defaults, err := load()
+	if err != nil {
+		t.Fatalf("load defaults: %v", err)
+	}
+	if got, want := loaded.Metrics.SizeBuckets, defaults.Metrics.SizeBuckets; !reflect.DeepEqual(got, want) {
+		t.Errorf("metrics.size_buckets = %v, default %v", got, want)
+	}
+	if got, want := loaded.Metrics.DurationBuckets, defaults.Metrics.DurationBuckets; !reflect.DeepEqual(got, want) {
+		t.Errorf("metrics.duration_buckets = %v, default %v", got, want)
+	}
+}
+
+func TestUnknownKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		unused []string
+		want   []string
+	}{
+		{name: "无未使用的键", unused: nil, want: nil},
+		{name: "排序", unused: []string{"redis.hots", "app.nmae"}, want: []string{"app.nmae", "redis.hots"}},
+		{name: "保留段及其子键", unused: []string{"aliyun.oss", "aliyun.oss.bucket", "aliyun.cms.namespace"}, want: nil},
+		{name: "前缀相同但不是保留段", unused: []string{"aliyun.ossx"}, want: []string{"aliyun.ossx"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := unknownKeys(tt.unused)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("unknownKeys(%q) = %q, want %q", tt.unused, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// ChangeHandler 配置变更回调，old/new 均为只读快照
+type ChangeHandler func(old, new *Config)
+
+// ReloadResult 一次重载的结果
+type ReloadResult struct {
+	// Applied 已生效的热更新配置项
+	Applied []string
+	// Ignored 发生变更但需重启才能生效的配置段
+	Ignored []string
+	// Err 重载失败原因（解析或校验失败），失败时保留旧配置
+	Err error
+}
+
+// reloadDelay 文件变化后等待的时间，用于合并连续的写入事件
+const reloadDelay = 200 * time.Millisecond
+
+var (
+	handlersMu sync.RWMutex
+	handlers   []ChangeHandler
+
+	reloadMu sync.Mutex
+)
+
+// hotReloadable 支持热更新的配置项：name 为配置路径，apply 把新值复制到当前配置
+var hotReloadable = []struct {
+	name  string
+	apply func(dst, src *Config)
+}{
+	{"log.level", func(dst, src *Config) { dst.Log.Level = src.Log.Level }},
+	{"log.http", func(dst, src *Config) { dst.Log.HTTP = src.Log.HTTP }},
+	{"log.modules", func(dst, src *Config) { dst.Log.Modules = src.Log.Modules }},
+	{"features", func(dst, src *Config) { dst.Features = src.Features }},
+	{"security.cors", func(dst, src *Config) { dst.Security.CORS = src.Security.CORS }},
+	{"performance.rate_limit", func(dst, src *Config) { dst.Performance.RateLimit = src.Performance.RateLimit }},
+}
+
+// OnChange 订阅配置变更，热更新配置项生效后回调
+func OnChange(handler ChangeHandler) {
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
+
+	handlers = append(handlers, handler)
+}
+
+// Watch 监听配置文件（含环境覆盖配置）变化并自动重载，report 用于输出每次重载的结果
+func Watch(report func(ReloadResult)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	// 监听所在目录而不是文件本身，以兼容编辑器替换文件和Kubernetes ConfigMap的符号链接切换
+	watched := make(map[string]bool)
+	dirs := make(map[string]bool)
+	for _, file := range Files() {
+		watched[filepath.Clean(file)] = true
+		dir := filepath.Dir(file)
+		if dirs[dir] {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return err
+		}
+		dirs[dir] = true
+	}
+
+	go func() {
+		var timer *time.Timer
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !watched[filepath.Clean(event.Name)] && filepath.Base(event.Name) != "..data" {
+					continue
+				}
+				// 一次保存通常触发多个事件，合并后只重载一次
+				if timer != nil {
+					timer.Stop()
+				}
+				timer = time.AfterFunc(reloadDelay, func() {
+					result := Reload()
+					if report != nil {
+						report(result)
+					}
+				})
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Reload 重新解析并校验配置：只应用支持热更新的配置项，其余变更记录后忽略，直到重启
+func Reload() ReloadResult {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	if err := readInConfig(); err != nil {
+		return ReloadResult{Err: err}
+	}
+
+	loaded, err := load()
+	if err != nil {
+		return ReloadResult{Err: err}
+	}
+
+	old := Get()
+	next := *old
+
+	var result ReloadResult
+	for _, item := range hotReloadable {
+		before := next
+		item.apply(&next, loaded)
+		if !reflect.DeepEqual(before, next) {
+			result.Applied = append(result.Applied, item.name)
+		}
+	}
+
+	// 把热更新项都同步后，剩余差异即为需要重启的配置段
+	rest := *loaded
+	for _, item := range hotReloadable {
+		item.apply(&rest, &next)
+	}
+	result.Ignored = changedSections(&next, &rest)
+
+	if len(result.Applied) == 0 {
+		return result
+	}
+
+	cfg.Store(&next)
+
+	handlersMu.RLock()
+	subscribers := append([]ChangeHandler{}, handlers...)
+	handlersMu.RUnlock()
+
+	for _, handler := range subscribers {
+		handler(old, &next)
+	}
+
+	return result
+}
+
+// changedSections 比较两份配置，返回有差异的顶级配置段
+func changedSections(a, b *Config) []string {
+	var sections []string
+
+	va := reflect.ValueOf(a).Elem()
+	vb := reflect.ValueOf(b).Elem()
+	t := va.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if !reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			sections = append(sections, t.Field(i).Tag.Get("mapstructure"))
+		}
+	}
+
+	sort.Strings(sections)
+	return sections
+}
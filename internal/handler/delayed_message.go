@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"ocean-marketing/internal/pkg/redis"
+	"ocean-marketing/pkg/errno"
+	"ocean-marketing/pkg/mq"
+	"ocean-marketing/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DelayedMessageHandler 延迟消息管理控制器
+type DelayedMessageHandler struct {
+	scheduler *mq.DelayScheduler
+}
+
+// NewDelayedMessageHandler 创建延迟消息管理控制器实例
+func NewDelayedMessageHandler() *DelayedMessageHandler {
+	return &DelayedMessageHandler{
+		scheduler: mq.NewDelayScheduler(redis.GetClient()),
+	}
+}
+
+// GetDelayedMessages 获取等待中的延迟消息
+// @Summary 获取延迟消息列表
+// @Description 按到期时间顺序分页获取尚未发布的延迟消息（仅redis延迟模式）
+// @Tags 系统管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(20)
+// @Success 200 {object} response.Response{data=response.PageResponse} "获取成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /api/v1/admin/delayed-messages [get]
+func (h *DelayedMessageHandler) GetDelayedMessages(c *gin.Context) {
+	page := 1
+	size := 20
+
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	if sizeStr := c.Query("size"); sizeStr != "" {
+		if s, err := strconv.Atoi(sizeStr); err == nil && s > 0 && s <= 100 {
+			size = s
+		}
+	}
+
+	list, total, err := h.scheduler.List(c, int64((page-1)*size), int64(size))
+	if err != nil {
+		response.Error(c, errno.ErrRedis)
+		return
+	}
+
+	response.SuccessWithPage(c, list, total, page, size)
+}
+
+// CancelDelayedMessage 取消延迟消息
+// @Summary 取消延迟消息
+// @Description 取消尚未发布的延迟消息
+// @Tags 系统管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "消息ID"
+// @Success 200 {object} response.Response "取消成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "消息不存在或已发布"
+// @Failure 409 {object} response.Response "消息已到期，正在发布"
+// @Router /api/v1/admin/delayed-messages/{id} [delete]
+func (h *DelayedMessageHandler) CancelDelayedMessage(c *gin.Context) {
+	cancelled, err := h.scheduler.Cancel(c, c.Param("id"))
+	if errors.Is(err, mq.ErrDelayClaimed) {
+		response.ErrorWithCode(c, http.StatusConflict, errno.ErrResourceConflict)
+		return
+	}
+	if err != nil {
+		response.Error(c, errno.ErrRedis)
+		return
+	}
+
+	if !cancelled {
+		response.NotFound(c, errno.ErrResourceNotFound)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "取消成功"})
+}
@@ -51,7 +51,7 @@ func (h *ExampleHandler) GetExamples(c *gin.Context) {
 		}
 	}
 
-	list, total, err := h.exampleService.GetList(page, size)
+	list, total, err := h.exampleService.GetList(c.Request.Context(), page, size)
 	if err != nil {
 		response.Error(c, err)
 		return
@@ -79,7 +79,7 @@ func (h *ExampleHandler) GetExample(c *gin.Context) {
 		return
 	}
 
-	example, err := h.exampleService.GetByID(uint(id))
+	example, err := h.exampleService.GetByID(c.Request.Context(), uint(id))
 	if err != nil {
 		response.Error(c, err)
 		return
@@ -115,7 +115,7 @@ func (h *ExampleHandler) CreateExample(c *gin.Context) {
 
 	// 创建示例，使用userID作为创建者
 	currentUser := strconv.FormatUint(uint64(userID), 10)
-	example, err := h.exampleService.Create(&req, currentUser)
+	example, err := h.exampleService.Create(c.Request.Context(), &req, currentUser)
 	if err != nil {
 		response.Error(c, err)
 		return
@@ -161,7 +161,7 @@ func (h *ExampleHandler) UpdateExample(c *gin.Context) {
 
 	// 更新示例，使用userID作为当前用户
 	currentUser := strconv.FormatUint(uint64(userID), 10)
-	example, err := h.exampleService.Update(uint(id), &req, currentUser)
+	example, err := h.exampleService.Update(c.Request.Context(), uint(id), &req, currentUser)
 	if err != nil {
 		response.Error(c, err)
 		return
@@ -200,7 +200,7 @@ func (h *ExampleHandler) DeleteExample(c *gin.Context) {
 
 	// 删除示例，使用userID作为当前用户
 	currentUser := strconv.FormatUint(uint64(userID), 10)
-	if err := h.exampleService.Delete(uint(id), currentUser); err != nil {
+	if err := h.exampleService.Delete(c.Request.Context(), uint(id), currentUser); err != nil {
 		response.Error(c, err)
 		return
 	}
@@ -54,3 +54,7 @@ func Ready(c *gin.Context) {
 func Live(c *gin.Context) {
 	LivenessCheck(c)
 }
+
+func Version(c *gin.Context) {
+	VersionInfo(c)
+}
@@ -2,25 +2,27 @@ package handler
 
 import (
 	"net/http"
+	"sort"
 	"time"
 
-	"ocean-marketing/internal/pkg/database"
-	"ocean-marketing/internal/pkg/redis"
+	"ocean-marketing/internal/pkg/health"
+	"ocean-marketing/internal/pkg/lifecycle"
+	"ocean-marketing/internal/pkg/version"
 
 	"github.com/gin-gonic/gin"
 )
 
 // HealthResponse 健康检查响应
 type HealthResponse struct {
-	Status    string            `json:"status"`
-	Timestamp time.Time         `json:"timestamp"`
-	Services  map[string]string `json:"services"`
-	Version   string            `json:"version"`
+	Status    string                   `json:"status"`
+	Timestamp time.Time                `json:"timestamp"`
+	Checks    map[string]health.Result `json:"checks"`
+	Version   string                   `json:"version"`
 }
 
 // HealthCheck 健康检查
 // @Summary 健康检查
-// @Description 检查服务及其依赖的健康状态
+// @Description 检查服务及其依赖的健康状态和每项检查的耗时，不含错误详情（见运维端口 /debug/health）；只有非关键依赖异常时为 degraded
 // @Tags 系统
 // @Accept json
 // @Produce json
@@ -28,45 +30,36 @@ type HealthResponse struct {
 // @Failure 503 {object} HealthResponse "服务异常"
 // @Router /health [get]
 func HealthCheck(c *gin.Context) {
-	services := make(map[string]string)
-	overallStatus := "healthy"
-
-	// 检查数据库连接
-	if db := database.GetDB(); db != nil {
-		sqlDB, err := db.DB()
-		if err != nil || sqlDB.Ping() != nil {
-			services["database"] = "unhealthy"
-			overallStatus = "unhealthy"
-		} else {
-			services["database"] = "healthy"
-		}
-	} else {
-		services["database"] = "unhealthy"
-		overallStatus = "unhealthy"
-	}
+	writeHealth(c, false)
+}
+
+// HealthDetail 健康检查详情，包含各项检查的错误信息，只在运维端点提供
+func HealthDetail(c *gin.Context) {
+	writeHealth(c, true)
+}
+
+// writeHealth 执行健康检查并返回报告，detail为false时隐藏错误信息，避免对外暴露内部地址等信息
+func writeHealth(c *gin.Context, detail bool) {
+	report := health.Run(c.Request.Context())
 
-	// 检查Redis连接
-	if redisClient := redis.GetClient(); redisClient != nil {
-		if _, err := redisClient.Ping(c).Result(); err != nil {
-			services["redis"] = "unhealthy"
-			overallStatus = "unhealthy"
-		} else {
-			services["redis"] = "healthy"
+	checks := report.Checks
+	if !detail {
+		checks = make(map[string]health.Result, len(report.Checks))
+		for name, result := range report.Checks {
+			result.Error = ""
+			checks[name] = result
 		}
-	} else {
-		services["redis"] = "unhealthy"
-		overallStatus = "unhealthy"
 	}
 
 	response := HealthResponse{
-		Status:    overallStatus,
-		Timestamp: time.Now(),
-		Services:  services,
-		Version:   "1.0.0",
+		Status:    report.Status,
+		Timestamp: report.CheckedAt,
+		Checks:    checks,
+		Version:   version.Version,
 	}
 
 	statusCode := http.StatusOK
-	if overallStatus == "unhealthy" {
+	if report.Status == health.StatusUnhealthy {
 		statusCode = http.StatusServiceUnavailable
 	}
 
@@ -75,7 +68,7 @@ func HealthCheck(c *gin.Context) {
 
 // ReadinessCheck 就绪检查
 // @Summary 就绪检查
-// @Description 检查服务是否准备好接收请求
+// @Description 检查服务是否准备好接收请求：启动已完成、未开始关闭且关键依赖正常
 // @Tags 系统
 // @Accept json
 // @Produce json
@@ -83,7 +76,35 @@ func HealthCheck(c *gin.Context) {
 // @Failure 503 {object} map[string]interface{} "服务未就绪"
 // @Router /ready [get]
 func ReadinessCheck(c *gin.Context) {
-	// 简单的就绪检查，可以根据需要扩展
+	// 启动完成前和开始关闭后返回未就绪，负载均衡据此摘除流量
+	if !lifecycle.Ready() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":    "not_ready",
+			"reason":    "starting_or_stopping",
+			"timestamp": time.Now(),
+		})
+		return
+	}
+
+	report := health.Run(c.Request.Context())
+	if report.Status == health.StatusUnhealthy {
+		var failed []string
+		for name, result := range report.Checks {
+			if result.Critical && result.Status != health.StatusHealthy {
+				failed = append(failed, name)
+			}
+		}
+		sort.Strings(failed)
+
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":    "not_ready",
+			"reason":    "critical_check_failed",
+			"failed":    failed,
+			"timestamp": report.CheckedAt,
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"status":    "ready",
 		"timestamp": time.Now(),
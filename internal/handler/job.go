@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"strconv"
+
+	"ocean-marketing/internal/service"
+	"ocean-marketing/pkg/errno"
+	"ocean-marketing/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JobHandler 后台任务管理控制器
+type JobHandler struct {
+	jobService *service.JobService
+}
+
+// NewJobHandler 创建后台任务管理控制器实例
+func NewJobHandler() *JobHandler {
+	return &JobHandler{
+		jobService: service.NewJobService(),
+	}
+}
+
+// GetJobs 获取任务列表
+// @Summary 获取任务列表
+// @Description 分页获取后台任务，可按状态和名称过滤
+// @Tags 系统管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param status query string false "状态" Enums(pending, running, succeeded, failed, cancelled)
+// @Param name query string false "任务名称"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(20)
+// @Success 200 {object} response.Response{data=response.PageResponse} "获取成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /api/v1/admin/jobs [get]
+func (h *JobHandler) GetJobs(c *gin.Context) {
+	page := 1
+	size := 20
+
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	if sizeStr := c.Query("size"); sizeStr != "" {
+		if s, err := strconv.Atoi(sizeStr); err == nil && s > 0 && s <= 100 {
+			size = s
+		}
+	}
+
+	list, total, err := h.jobService.GetList(c.Request.Context(), c.Query("status"), c.Query("name"), page, size)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithPage(c, list, total, page, size)
+}
+
+// GetJob 获取任务详情
+// @Summary 获取任务详情
+// @Description 根据ID获取后台任务
+// @Tags 系统管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "任务ID"
+// @Success 200 {object} response.Response{data=model.Job} "获取成功"
+// @Failure 404 {object} response.Response "任务不存在"
+// @Router /api/v1/admin/jobs/{id} [get]
+func (h *JobHandler) GetJob(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, errno.ErrBind)
+		return
+	}
+
+	job, err := h.jobService.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, job)
+}
+
+// RetryJob 重试任务
+// @Summary 重试任务
+// @Description 重新执行失败或已取消的任务
+// @Tags 系统管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "任务ID"
+// @Success 200 {object} response.Response{data=model.Job} "重试成功"
+// @Failure 404 {object} response.Response "任务不存在"
+// @Router /api/v1/admin/jobs/{id}/retry [post]
+func (h *JobHandler) RetryJob(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, errno.ErrBind)
+		return
+	}
+
+	job, err := h.jobService.Retry(c.Request.Context(), uint(id))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, job)
+}
+
+// CancelJob 取消任务
+// @Summary 取消任务
+// @Description 取消等待中或执行中的任务
+// @Tags 系统管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "任务ID"
+// @Success 200 {object} response.Response{data=model.Job} "取消成功"
+// @Failure 404 {object} response.Response "任务不存在"
+// @Router /api/v1/admin/jobs/{id}/cancel [post]
+func (h *JobHandler) CancelJob(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, errno.ErrBind)
+		return
+	}
+
+	job, err := h.jobService.Cancel(c.Request.Context(), uint(id))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, job)
+}
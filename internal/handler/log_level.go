@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"time"
+
+	"ocean-marketing/internal/middleware"
+	"ocean-marketing/internal/pkg/logger"
+	"ocean-marketing/pkg/errno"
+	"ocean-marketing/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// LogLevelRequest 调整日志级别请求
+type LogLevelRequest struct {
+	// Module 模块名（logger.Named 的参数），为空时调整全局级别
+	Module string `json:"module" binding:"omitempty,max=64"`
+	// Level 日志级别，调整模块时为空表示恢复为跟随全局级别
+	Level string `json:"level" binding:"omitempty,oneof=debug info warn error fatal"`
+	// RevertAfter 多少分钟后自动恢复为调整前的级别，0表示不自动恢复
+	RevertAfter int `json:"revert_after" binding:"min=0,max=1440"`
+}
+
+// LogLevelHandler 日志级别管理控制器
+type LogLevelHandler struct{}
+
+// NewLogLevelHandler 创建日志级别管理控制器实例
+func NewLogLevelHandler() *LogLevelHandler {
+	return &LogLevelHandler{}
+}
+
+// GetLogLevel 获取日志级别
+// @Summary 获取日志级别
+// @Description 获取全局和各模块的日志级别，以及计划自动恢复的时间
+// @Tags 系统管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=logger.LevelStatus} "获取成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /api/v1/admin/log-level [get]
+func (h *LogLevelHandler) GetLogLevel(c *gin.Context) {
+	response.Success(c, logger.Levels())
+}
+
+// SetLogLevel 调整日志级别
+// @Summary 调整日志级别
+// @Description 调整全局或模块的日志级别，可指定分钟数到期自动恢复；重启或配置重载后以配置为准
+// @Tags 系统管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body LogLevelRequest true "日志级别"
+// @Success 200 {object} response.Response{data=logger.LevelStatus} "调整成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "权限不足"
+// @Router /api/v1/admin/log-level [put]
+func (h *LogLevelHandler) SetLogLevel(c *gin.Context) {
+	var req LogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, errno.ErrBind)
+		return
+	}
+
+	if err := logger.ChangeLevel(req.Module, req.Level, time.Duration(req.RevertAfter)*time.Minute); err != nil {
+		response.BadRequest(c, errno.ErrValidation)
+		return
+	}
+
+	// 运维端点使用basic认证时取basic用户名
+	operator := middleware.GetCurrentUsername(c)
+	if operator == "" {
+		operator = c.GetString(gin.AuthUserKey)
+	}
+	logger.FromContext(c.Request.Context()).Info("管理接口调整日志级别",
+		zap.String("operator", operator),
+		zap.String("module", req.Module),
+		zap.String("level", req.Level),
+		zap.Int("revert_after", req.RevertAfter))
+
+	response.Success(c, logger.Levels())
+}
@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"net/http"
+
+	"ocean-marketing/internal/pkg/version"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VersionInfo 版本信息
+// @Summary 版本信息
+// @Description 获取当前运行的版本、Git提交和构建时间
+// @Tags 系统
+// @Accept json
+// @Produce json
+// @Success 200 {object} version.Info "获取成功"
+// @Router /version [get]
+func VersionInfo(c *gin.Context) {
+	c.JSON(http.StatusOK, version.Get())
+}
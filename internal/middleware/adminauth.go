@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net"
+	"net/netip"
+
+	"ocean-marketing/internal/config"
+	"ocean-marketing/pkg/errno"
+	"ocean-marketing/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminAuth 运维端点访问控制：配置了 allow_ips 时校验TCP连接的对端地址（不信任 X-Forwarded-For），
+// 配置了 username 时要求basic认证，两者都配置时需同时满足
+func AdminAuth(cfg config.AdminConfig) gin.HandlerFunc {
+	var prefixes []netip.Prefix
+	for _, ip := range cfg.AllowIPs {
+		if prefix, err := netip.ParsePrefix(ip); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(ip); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+		}
+	}
+
+	var basicAuth gin.HandlerFunc
+	if cfg.Username != "" {
+		basicAuth = gin.BasicAuthForRealm(gin.Accounts{cfg.Username: cfg.Password}, "admin")
+	}
+
+	return func(c *gin.Context) {
+		if len(prefixes) > 0 && !allowedIP(prefixes, c.Request.RemoteAddr) {
+			response.Forbidden(c, errno.ErrPermissionDenied)
+			c.Abort()
+			return
+		}
+
+		if basicAuth != nil {
+			basicAuth(c)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// allowedIP 对端地址是否在白名单中
+func allowedIP(prefixes []netip.Prefix, remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"ocean-marketing/internal/config"
+	"ocean-marketing/internal/pkg/logger"
 	"ocean-marketing/pkg/errno"
 	"ocean-marketing/pkg/jwt"
 	"ocean-marketing/pkg/response"
@@ -41,6 +42,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		// 将用户信息保存到上下文
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
+		c.Request = c.Request.WithContext(logger.WithUserID(c.Request.Context(), claims.UserID))
 
 		c.Next()
 	}
@@ -89,6 +91,7 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 		// 将用户信息保存到上下文
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
+		c.Request = c.Request.WithContext(logger.WithUserID(c.Request.Context(), claims.UserID))
 
 		c.Next()
 	}
@@ -101,3 +104,21 @@ func RequireAuth(cfg *config.Config) gin.HandlerFunc {
 
 	return AuthMiddleware()
 }
+
+// RequireAdmin 管理员校验中间件，需在AuthMiddleware之后使用
+func RequireAdmin(cfg *config.Config) gin.HandlerFunc {
+	admins := make(map[string]bool, len(cfg.App.AdminUsers))
+	for _, username := range cfg.App.AdminUsers {
+		admins[username] = true
+	}
+
+	return func(c *gin.Context) {
+		if !admins[GetCurrentUsername(c)] {
+			response.Forbidden(c, errno.ErrPermissionDenied)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -1,26 +1,147 @@
 package middleware
 
 import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"ocean-marketing/internal/config"
+
 	"github.com/gin-gonic/gin"
 )
 
-// CORS 跨域中间件
-func CORS() gin.HandlerFunc {
+// corsPolicy 预处理后的跨域规则
+type corsPolicy struct {
+	allowAll         bool
+	origins          map[string]bool
+	wildcards        []wildcardOrigin
+	allowMethods     string
+	allowHeaders     string
+	exposeHeaders    string
+	maxAge           string
+	allowCredentials bool
+}
+
+// wildcardOrigin 子域名通配规则，如 https://*.example.com
+type wildcardOrigin struct {
+	scheme string
+	suffix string
+}
+
+// newCORSPolicy 根据配置生成跨域规则
+func newCORSPolicy(cfg config.CORSConfig) *corsPolicy {
+	p := &corsPolicy{
+		origins:          make(map[string]bool),
+		allowMethods:     strings.Join(cfg.AllowMethods, ", "),
+		allowHeaders:     strings.Join(cfg.AllowHeaders, ", "),
+		exposeHeaders:    strings.Join(cfg.ExposeHeaders, ", "),
+		allowCredentials: cfg.AllowCredentials,
+	}
+	if cfg.MaxAge > 0 {
+		p.maxAge = strconv.Itoa(cfg.MaxAge)
+	}
+
+	for _, origin := range cfg.AllowOrigins {
+		origin = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(origin), "/"))
+		if origin == "*" {
+			p.allowAll = true
+			continue
+		}
+		if scheme, host, ok := strings.Cut(origin, "://*."); ok {
+			p.wildcards = append(p.wildcards, wildcardOrigin{scheme: scheme, suffix: "." + host})
+			continue
+		}
+		p.origins[origin] = true
+	}
+
+	return p
+}
+
+// allowed 判断来源是否被允许
+func (p *corsPolicy) allowed(origin string) bool {
+	if p.allowAll {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+	if p.origins[origin] {
+		return true
+	}
+
+	scheme, host, ok := strings.Cut(origin, "://")
+	if !ok {
+		return false
+	}
+	for _, w := range p.wildcards {
+		// 只匹配子域名，不匹配根域名本身
+		if scheme == w.scheme && strings.HasSuffix(host, w.suffix) && len(host) > len(w.suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// CORS 跨域中间件，按 security.cors 配置的来源白名单放行，配置热更新后立即生效
+func CORS(cfg config.CORSConfig) gin.HandlerFunc {
+	var policy atomic.Pointer[corsPolicy]
+	policy.Store(newCORSPolicy(cfg))
+
+	config.OnChange(func(old, new *config.Config) {
+		policy.Store(newCORSPolicy(new.Security.CORS))
+	})
+
 	return func(c *gin.Context) {
-		method := c.Request.Method
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			// 非跨域请求
+			c.Next()
+			return
+		}
 
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Headers", "Content-Type,AccessToken,X-CSRF-Token, Authorization, Token, x-requested-with")
-		c.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS, DELETE, PUT, PATCH")
-		c.Header("Access-Control-Expose-Headers", "Content-Length, Access-Control-Allow-Origin, Access-Control-Allow-Headers, Content-Type")
-		c.Header("Access-Control-Allow-Credentials", "true")
+		p := policy.Load()
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
 
-		// 放行所有OPTIONS方法
-		if method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		// 响应随来源不同而不同，避免被缓存后返回给其他来源
+		echoOrigin := !p.allowAll || p.allowCredentials
+		if echoOrigin {
+			c.Writer.Header().Add("Vary", "Origin")
+		}
+
+		if !p.allowed(origin) {
+			// 不在白名单中的来源不返回CORS响应头，由浏览器拦截
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
 			return
 		}
 
+		// 允许凭证时必须回显具体来源
+		if echoOrigin {
+			c.Header("Access-Control-Allow-Origin", origin)
+		} else {
+			c.Header("Access-Control-Allow-Origin", "*")
+		}
+		if p.allowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		if preflight {
+			c.Header("Access-Control-Allow-Methods", p.allowMethods)
+			c.Header("Access-Control-Allow-Headers", p.allowHeaders)
+			if p.maxAge != "" {
+				c.Header("Access-Control-Max-Age", p.maxAge)
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		if p.exposeHeaders != "" {
+			c.Header("Access-Control-Expose-Headers", p.exposeHeaders)
+		}
+
 		c.Next()
 	}
 }
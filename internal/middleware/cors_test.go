@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ocean-marketing/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCORSPolicyAllowed(t *testing.T) {
+	tests := []struct {
+		name    string
+		origins []string
+		origin  string
+		want    bool
+	}{
+		{name: "任意来源", origins: []string{"*"}, origin: "https://evil.example", want: true},
+		{name: "精确匹配", origins: []string{"https://app.example.com"}, origin: "https://app.example.com", want: true},
+		{name: "忽略大小写和配置的末尾斜杠", origins: []string{"https://App.Example.com/"}, origin: "https://app.EXAMPLE.com", want: true},
+		{name: "协议不同", origins: []string{"https://app.example.com"}, origin: "http://app.example.com", want: false},
+		{name: "端口不同", origins: []string{"https://app.example.com"}, origin: "https://app.example.com:8443", want: false},
+		{name: "子域名通配", origins: []string{"https://*.example.com"}, origin: "https://a.b.example.com", want: true},
+		{name: "通配不匹配根域名", origins: []string{"https://*.example.com"}, origin: "https://example.com", want: false},
+		{name: "通配不匹配相似域名", origins: []string{"https://*.example.com"}, origin: "https://evilexample.com", want: false},
+		{name: "通配不匹配后缀伪造", origins: []string{"https://*.example.com"}, origin: "https://example.com.evil.io", want: false},
+		{name: "通配协议不同", origins: []string{"https://*.example.com"}, origin: "http://a.example.com", want: false},
+		{name: "无协议的来源", origins: []string{"https://*.example.com"}, origin: "a.example.com", want: false},
+		{name: "未配置", origins: nil, origin: "https://app.example.com", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newCORSPolicy(config.CORSConfig{AllowOrigins: tt.origins})
+			if got := p.allowed(tt.origin); got != tt.want {
+				t.Errorf("allowed(%q) with %q = %v, want %v", tt.origin, tt.origins, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCORS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.CORSConfig{
+		AllowOrigins:     []string{"https://*.example.com"},
+		AllowMethods:     []string{"GET", "POST"},
+		AllowHeaders:     []string{"Authorization", "Content-Type"},
+		ExposeHeaders:    []string{"X-Request-ID"},
+		MaxAge:           600,
+		AllowCredentials: true,
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		origin      string
+		preflight   bool
+		wantStatus  int
+		wantOrigin  string
+		wantMethods string
+		wantExpose  string
+	}{
+		{name: "同源请求", method: http.MethodGet, wantStatus: http.StatusOK},
+		{name: "允许的来源", method: http.MethodGet, origin: "https://app.example.com", wantStatus: http.StatusOK,
+			wantOrigin: "https://app.example.com", wantExpose: "X-Request-ID"},
+		{name: "不允许的来源不返回CORS头", method: http.MethodGet, origin: "https://evil.io", wantStatus: http.StatusOK},
+		{name: "允许的预检", method: http.MethodOptions, origin: "https://app.example.com", preflight: true,
+			wantStatus: http.StatusNoContent, wantOrigin: "https://app.example.com", wantMethods: "GET, POST"},
+		{name: "拒绝的预检", method: http.MethodOptions, origin: "https://evil.io", preflight: true, wantStatus: http.StatusForbidden},
+	}
+
+	r := gin.New()
+	r.Use(CORS(cfg))
+	r.Any("/api", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Methods"); got != tt.wantMethods {
+				t.Errorf("Allow-Methods = %q, want %q", got, tt.wantMethods)
+			}
+			if got := w.Header().Get("Access-Control-Expose-Headers"); got != tt.wantExpose {
+				t.Errorf("Expose-Headers = %q, want %q", got, tt.wantExpose)
+			}
+
+			wantCredentials := ""
+			if tt.wantOrigin != "" {
+				wantCredentials = "true"
+			}
+			if got := w.Header().Get("Access-Control-Allow-Credentials"); got != wantCredentials {
+				t.Errorf("Allow-Credentials = %q, want %q", got, wantCredentials)
+			}
+			if tt.origin != "" && w.Header().Get("Vary") != "Origin" {
+				t.Errorf("Vary = %q, want Origin", w.Header().Get("Vary"))
+			}
+		})
+	}
+}
@@ -3,44 +3,161 @@ package middleware
 import (
 	"bytes"
 	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"ocean-marketing/internal/config"
 	"ocean-marketing/internal/pkg/logger"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
-// responseWriter 包装响应写入器
+// 请求/响应体记录方式
+const (
+	bodyModeOff     = "off"
+	bodyModeErrors  = "errors"
+	bodyModeSampled = "sampled"
+	bodyModeAll     = "all"
+)
+
+// responseWriter 包装响应写入器，最多保留limit字节的响应体
 type responseWriter struct {
 	gin.ResponseWriter
-	body *bytes.Buffer
+	body      bytes.Buffer
+	limit     int
+	truncated bool
 }
 
-func (w responseWriter) Write(b []byte) (int, error) {
-	w.body.Write(b)
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.capture(b)
 	return w.ResponseWriter.Write(b)
 }
 
-// Logger 日志中间件
-func Logger() gin.HandlerFunc {
+func (w *responseWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *responseWriter) capture(b []byte) {
+	if remaining := w.limit - w.body.Len(); remaining < len(b) {
+		w.truncated = true
+		b = b[:max(remaining, 0)]
+	}
+	w.body.Write(b)
+}
+
+// httpLogPolicy 编译后的请求日志配置，配置热更新时整体替换
+type httpLogPolicy struct {
+	bodyMode     string
+	sampleRate   float64
+	maxBodySize  int
+	contentTypes []string
+	logHeaders   bool
+	redactor     *redactor
+}
+
+// newHTTPLogPolicy 根据配置生成请求日志策略
+func newHTTPLogPolicy(cfg config.HTTPLogConfig) *httpLogPolicy {
+	contentTypes := make([]string, 0, len(cfg.ContentTypes))
+	for _, contentType := range cfg.ContentTypes {
+		contentTypes = append(contentTypes, strings.ToLower(contentType))
+	}
+
+	return &httpLogPolicy{
+		bodyMode:     cfg.BodyMode,
+		sampleRate:   cfg.SampleRate,
+		maxBodySize:  cfg.MaxBodySize,
+		contentTypes: contentTypes,
+		logHeaders:   cfg.LogHeaders,
+		redactor:     newRedactor(cfg.RedactFields, cfg.RedactHeaders),
+	}
+}
+
+// capture 是否需要采集本次请求的请求/响应体，errors 模式需先采集、请求结束后再决定是否记录
+func (p *httpLogPolicy) capture() bool {
+	switch p.bodyMode {
+	case bodyModeAll, bodyModeErrors:
+		return p.maxBodySize > 0
+	case bodyModeSampled:
+		return p.maxBodySize > 0 && rand.Float64() < p.sampleRate
+	}
+	return false
+}
+
+// loggable 该内容类型是否记录内容，其他类型（如文件上传）只记录长度
+func (p *httpLogPolicy) loggable(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, prefix := range p.contentTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// peekRequestBody 最多读取maxBodySize字节的请求体，读取的部分拼回请求体，后续处理不受影响
+func (p *httpLogPolicy) peekRequestBody(req *http.Request) (body []byte, truncated bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, false
+	}
+
+	peeked, err := io.ReadAll(io.LimitReader(req.Body, int64(p.maxBodySize)+1))
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(peeked), req.Body), req.Body}
+	if err != nil {
+		return nil, false
+	}
+
+	if len(peeked) > p.maxBodySize {
+		return peeked[:p.maxBodySize], true
+	}
+	return peeked, false
+}
+
+// formatBody 脱敏并标记截断
+func (p *httpLogPolicy) formatBody(contentType string, body []byte, truncated bool) string {
+	s := strings.ToValidUTF8(p.redactor.body(contentType, body, truncated), "")
+	if truncated {
+		s += "...(truncated)"
+	}
+	return s
+}
+
+// Logger 日志中间件，按 log.http 配置记录请求/响应体并脱敏，配置热更新后立即生效
+func Logger(cfg config.HTTPLogConfig) gin.HandlerFunc {
+	var policy atomic.Pointer[httpLogPolicy]
+	policy.Store(newHTTPLogPolicy(cfg))
+
+	config.OnChange(func(old, new *config.Config) {
+		policy.Store(newHTTPLogPolicy(new.Log.HTTP))
+	})
+
 	return func(c *gin.Context) {
 		// 开始时间
 		start := time.Now()
+		p := policy.Load()
+		capture := p.capture()
 
-		// 读取请求体
-		var bodyBytes []byte
-		if c.Request.Body != nil {
-			bodyBytes, _ = io.ReadAll(c.Request.Body)
-			c.Request.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+		// 读取请求体，只读取需要记录的部分
+		var requestBody []byte
+		var requestTruncated bool
+		requestLoggable := capture && p.loggable(c.ContentType())
+		if requestLoggable {
+			requestBody, requestTruncated = p.peekRequestBody(c.Request)
 		}
 
 		// 包装响应写入器
-		writer := &responseWriter{
-			ResponseWriter: c.Writer,
-			body:           bytes.NewBufferString(""),
+		var writer *responseWriter
+		if capture {
+			writer = &responseWriter{ResponseWriter: c.Writer, limit: p.maxBodySize}
+			c.Writer = writer
 		}
-		c.Writer = writer
 
 		// 处理请求
 		c.Next()
@@ -48,24 +165,41 @@ func Logger() gin.HandlerFunc {
 		// 结束时间
 		end := time.Now()
 		latency := end.Sub(start)
+		status := c.Writer.Status()
 
-		// 记录日志
+		// 记录日志，附带请求ID、trace ID和用户ID
+		log := logger.FromContext(c.Request.Context())
 		fields := []zap.Field{
 			zap.String("method", c.Request.Method),
 			zap.String("path", c.Request.URL.Path),
-			zap.String("query", c.Request.URL.RawQuery),
+			zap.String("query", p.redactor.query(c.Request.URL.RawQuery)),
 			zap.String("ip", c.ClientIP()),
 			zap.String("user_agent", c.Request.UserAgent()),
-			zap.Int("status", c.Writer.Status()),
+			zap.Int("status", status),
 			zap.Duration("latency", latency),
-			zap.String("request_body", string(bodyBytes)),
-			zap.String("response_body", writer.body.String()),
+			zap.Int64("request_size", c.Request.ContentLength),
+			zap.Int("response_size", c.Writer.Size()),
+		}
+
+		if p.logHeaders {
+			fields = append(fields, zap.Any("headers", p.redactor.header(c.Request.Header)))
+		}
+
+		failed := status >= http.StatusBadRequest || len(c.Errors) > 0
+		if capture && (p.bodyMode != bodyModeErrors || failed) {
+			if requestLoggable {
+				fields = append(fields, zap.String("request_body", p.formatBody(c.ContentType(), requestBody, requestTruncated)))
+			}
+			if contentType := c.Writer.Header().Get("Content-Type"); p.loggable(contentType) {
+				fields = append(fields, zap.String("response_body", p.formatBody(contentType, writer.body.Bytes(), writer.truncated)))
+			}
 		}
 
 		if len(c.Errors) > 0 {
-			logger.Error("请求处理错误", fields...)
+			fields = append(fields, zap.String("errors", c.Errors.String()))
+			log.Error("请求处理错误", fields...)
 		} else {
-			logger.Info("请求处理完成", fields...)
+			log.Info("请求处理完成", fields...)
 		}
 	}
 }
@@ -3,46 +3,29 @@ package middleware
 import (
 	"ocean-marketing/internal/config"
 
-	"github.com/gin-contrib/pprof"
 	"github.com/gin-gonic/gin"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
-	swaggerFiles "github.com/swaggo/files"
-	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
 // Register 注册中间件
 func Register(r *gin.Engine, cfg *config.Config) {
+	// 请求ID中间件，需最先执行，后续日志和错误响应都会带上请求ID
+	r.Use(RequestID())
+
+	// 链路追踪中间件，在日志之前执行，日志可以关联trace ID
+	r.Use(Tracer())
+
 	// CORS 跨域中间件
-	r.Use(CORS())
+	r.Use(CORS(cfg.Security.CORS))
 
 	// 日志中间件
-	r.Use(Logger())
+	r.Use(Logger(cfg.Log.HTTP))
 
 	// Recovery 中间件
-	r.Use(Recovery(cfg.Feishu))
+	r.Use(Recovery())
 
 	// 限流中间件
-	r.Use(RateLimit())
-
-	// 链路追踪中间件
-	r.Use(Tracer())
+	r.Use(RateLimit(cfg.Performance.RateLimit))
 
 	// Prometheus 指标中间件
-	r.Use(Prometheus())
-
-	// 健康检查
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
-	})
-
-	// Swagger 文档
-	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
-
-	// Prometheus 指标端点
-	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
-
-	// pprof 性能分析
-	if cfg.App.Mode == "debug" {
-		pprof.Register(r)
-	}
+	r.Use(Prometheus(cfg.Metrics))
 }
@@ -1,102 +1,132 @@
 package middleware
 
 import (
+	"net/http"
 	"strconv"
 	"time"
 
+	"ocean-marketing/internal/config"
+	"ocean-marketing/internal/pkg/metrics"
+
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
-var (
-	// HTTP请求总数
-	httpRequestsTotal = promauto.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "http_requests_total",
-			Help: "Total number of HTTP requests",
-		},
-		[]string{"method", "path", "status"},
-	)
+// unmatchedRoute 未匹配到路由的请求使用的path标签，避免扫描器请求的任意路径产生大量时间序列
+const unmatchedRoute = "unmatched"
+
+// knownMethods 标准HTTP方法，其他方法统一记为 OTHER
+var knownMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodConnect: true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
 
+// httpMetrics HTTP请求指标
+type httpMetrics struct {
+	// HTTP请求总数
+	requestsTotal *prometheus.CounterVec
 	// HTTP请求持续时间
-	httpRequestDuration = promauto.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Name:    "http_request_duration_seconds",
-			Help:    "Duration of HTTP requests in seconds",
-			Buckets: prometheus.DefBuckets,
-		},
-		[]string{"method", "path", "status"},
-	)
-
+	requestDuration *prometheus.HistogramVec
 	// HTTP请求大小
-	httpRequestSize = promauto.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Name:    "http_request_size_bytes",
-			Help:    "Size of HTTP requests in bytes",
-			Buckets: prometheus.ExponentialBuckets(100, 10, 8),
-		},
-		[]string{"method", "path"},
-	)
-
+	requestSize *prometheus.HistogramVec
 	// HTTP响应大小
-	httpResponseSize = promauto.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Name:    "http_response_size_bytes",
-			Help:    "Size of HTTP responses in bytes",
-			Buckets: prometheus.ExponentialBuckets(100, 10, 8),
-		},
-		[]string{"method", "path", "status"},
-	)
-
-	// 当前活跃连接数
-	httpActiveConnections = promauto.NewGauge(
-		prometheus.GaugeOpts{
-			Name: "http_active_connections",
-			Help: "Number of active HTTP connections",
-		},
-	)
-)
+	responseSize *prometheus.HistogramVec
+	// 正在处理的请求数
+	requestsInFlight prometheus.Gauge
+}
+
+// newHTTPMetrics 按 metrics 配置的分桶创建并注册HTTP指标
+func newHTTPMetrics(cfg config.MetricsConfig) *httpMetrics {
+	return &httpMetrics{
+		requestsTotal: metrics.MustRegister(prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "http_requests_total",
+				Help: "Total number of HTTP requests",
+			},
+			[]string{"method", "path", "status"},
+		)),
+		requestDuration: metrics.MustRegister(prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "http_request_duration_seconds",
+				Help:    "Duration of HTTP requests in seconds",
+				Buckets: cfg.DurationBuckets,
+			},
+			[]string{"method", "path", "status"},
+		)),
+		requestSize: metrics.MustRegister(prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "http_request_size_bytes",
+				Help:    "Size of HTTP requests in bytes",
+				Buckets: cfg.SizeBuckets,
+			},
+			[]string{"method", "path"},
+		)),
+		responseSize: metrics.MustRegister(prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "http_response_size_bytes",
+				Help:    "Size of HTTP responses in bytes",
+				Buckets: cfg.SizeBuckets,
+			},
+			[]string{"method", "path", "status"},
+		)),
+		requestsInFlight: metrics.MustRegister(prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "http_requests_in_flight",
+				Help: "Number of HTTP requests currently being served",
+			},
+		)),
+	}
+}
+
+// Prometheus 指标中间件，path标签使用路由模板，未匹配的路由统一记为 unmatched
+func Prometheus(cfg config.MetricsConfig) gin.HandlerFunc {
+	m := newHTTPMetrics(cfg)
 
-// Prometheus 指标中间件
-func Prometheus() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 开始时间
 		start := time.Now()
 
-		// 增加活跃连接数
-		httpActiveConnections.Inc()
+		// 增加处理中的请求数，处理过程中panic也能恢复
+		m.requestsInFlight.Inc()
+		defer m.requestsInFlight.Dec()
 
 		// 处理请求
 		c.Next()
 
-		// 减少活跃连接数
-		httpActiveConnections.Dec()
-
 		// 计算持续时间
 		duration := time.Since(start).Seconds()
 
 		// 获取标签值
 		method := c.Request.Method
+		if !knownMethods[method] {
+			method = "OTHER"
+		}
 		path := c.FullPath()
 		if path == "" {
-			path = c.Request.URL.Path
+			path = unmatchedRoute
 		}
 		status := strconv.Itoa(c.Writer.Status())
 
 		// 记录指标
-		httpRequestsTotal.WithLabelValues(method, path, status).Inc()
-		httpRequestDuration.WithLabelValues(method, path, status).Observe(duration)
+		m.requestsTotal.WithLabelValues(method, path, status).Inc()
+		m.requestDuration.WithLabelValues(method, path, status).Observe(duration)
 
 		// 记录请求大小
 		if c.Request.ContentLength > 0 {
-			httpRequestSize.WithLabelValues(method, path).Observe(float64(c.Request.ContentLength))
+			m.requestSize.WithLabelValues(method, path).Observe(float64(c.Request.ContentLength))
 		}
 
 		// 记录响应大小
 		responseSize := float64(c.Writer.Size())
 		if responseSize > 0 {
-			httpResponseSize.WithLabelValues(method, path, status).Observe(responseSize)
+			m.responseSize.WithLabelValues(method, path, status).Observe(responseSize)
 		}
 	}
 }
@@ -1,37 +1,257 @@
 package middleware
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"ocean-marketing/internal/config"
+	"ocean-marketing/internal/pkg/logger"
+	"ocean-marketing/internal/pkg/ratelimit"
+	"ocean-marketing/internal/pkg/redis"
 	"ocean-marketing/pkg/errno"
+	"ocean-marketing/pkg/jwt"
 	"ocean-marketing/pkg/response"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/ulule/limiter/v3"
 	"github.com/ulule/limiter/v3/drivers/store/memory"
+	"go.uber.org/zap"
 )
 
-// RateLimit 限流中间件
-func RateLimit() gin.HandlerFunc {
-	// 创建限流器，每分钟100个请求
-	rate := limiter.Rate{
-		Period: 1 * time.Minute,
-		Limit:  100,
+// 限流维度
+const (
+	rateLimitKeyUser   = "user"
+	rateLimitKeyAPIKey = "api_key"
+	rateLimitKeyIP     = "ip"
+)
+
+// defaultRateLimitPolicy 未匹配任何路由前缀时使用的策略名
+const defaultRateLimitPolicy = "default"
+
+var (
+	// 被限流拒绝的请求数
+	rateLimitRejectedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_rate_limit_rejected_total",
+			Help: "Total number of requests rejected by rate limiting",
+		},
+		[]string{"policy", "key_type", "window"},
+	)
+
+	// 限流存储出错（放行）的次数
+	rateLimitErrorsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_rate_limit_errors_total",
+			Help: "Total number of rate limit store errors, requests are allowed through",
+		},
+		[]string{"policy"},
+	)
+)
+
+// rateLimitPolicy 编译后的限流策略
+type rateLimitPolicy struct {
+	name     string
+	prefix   string
+	keyBy    string
+	disabled bool
+	// 每分钟限额
+	minute *limiter.Limiter
+	// 每秒限额（突发），为nil时不限制
+	second *limiter.Limiter
+}
+
+// rateLimitRules 限流规则，配置热更新时整体替换
+type rateLimitRules struct {
+	policies      []*rateLimitPolicy
+	fallback      *rateLimitPolicy
+	apiKeyHeader  string
+	apiKeys       map[string]bool
+	exemptNets    []*net.IPNet
+	exemptUsers   map[string]bool
+	exemptAPIKeys map[string]bool
+}
+
+// newRateLimitRules 根据配置生成限流规则，未启用时返回nil
+func newRateLimitRules(cfg config.RateLimitConfig, store limiter.Store) *rateLimitRules {
+	if !cfg.Enabled {
+		return nil
 	}
 
-	store := memory.NewStore()
-	instance := limiter.New(store, rate)
+	newPolicy := func(name, prefix, keyBy string, rate, burst int) *rateLimitPolicy {
+		if keyBy == "" {
+			keyBy = cfg.KeyBy
+		}
+		p := &rateLimitPolicy{
+			name:   name,
+			prefix: prefix,
+			keyBy:  keyBy,
+			minute: limiter.New(store, limiter.Rate{Period: time.Minute, Limit: int64(rate)}),
+		}
+		if burst > 0 {
+			p.second = limiter.New(store, limiter.Rate{Period: time.Second, Limit: int64(burst)})
+		}
+		return p
+	}
+
+	rules := &rateLimitRules{
+		fallback:      newPolicy(defaultRateLimitPolicy, "/", cfg.KeyBy, cfg.Rate, cfg.Burst),
+		apiKeyHeader:  cfg.APIKeyHeader,
+		apiKeys:       make(map[string]bool),
+		exemptUsers:   make(map[string]bool),
+		exemptAPIKeys: make(map[string]bool),
+	}
+
+	for name, policy := range cfg.Policies {
+		if policy.Disabled {
+			rules.policies = append(rules.policies, &rateLimitPolicy{name: name, prefix: policy.Prefix, disabled: true})
+			continue
+		}
+		rules.policies = append(rules.policies, newPolicy(name, policy.Prefix, policy.KeyBy, policy.Rate, policy.Burst))
+	}
+	// 最长前缀优先
+	sort.Slice(rules.policies, func(i, j int) bool {
+		return len(rules.policies[i].prefix) > len(rules.policies[j].prefix)
+	})
+
+	for _, ip := range cfg.ExemptIPs {
+		if !strings.Contains(ip, "/") {
+			if strings.Contains(ip, ":") {
+				ip += "/128"
+			} else {
+				ip += "/32"
+			}
+		}
+		if _, ipNet, err := net.ParseCIDR(ip); err == nil {
+			rules.exemptNets = append(rules.exemptNets, ipNet)
+		}
+	}
+	for _, user := range cfg.ExemptUsers {
+		rules.exemptUsers[user] = true
+	}
+	for _, key := range cfg.APIKeys {
+		rules.apiKeys[key] = true
+	}
+	for _, key := range cfg.ExemptAPIKeys {
+		rules.exemptAPIKeys[key] = true
+	}
+
+	return rules
+}
+
+// match 按请求路径匹配策略
+func (r *rateLimitRules) match(path string) *rateLimitPolicy {
+	for _, p := range r.policies {
+		if path == p.prefix || strings.HasPrefix(path, strings.TrimSuffix(p.prefix, "/")+"/") {
+			return p
+		}
+	}
+	return r.fallback
+}
+
+// identify 确定限流键，user 维度未登录时退化为 api_key，api_key 维度未携带或不是已分配的key时退化为 ip；
+// 客户端IP只在请求来自 app.trusted_proxies 时才取自 X-Forwarded-For
+func (r *rateLimitRules) identify(c *gin.Context, keyBy string) (keyType, id string, exempt bool) {
+	ip := c.ClientIP()
+	if parsed := net.ParseIP(ip); parsed != nil {
+		for _, ipNet := range r.exemptNets {
+			if ipNet.Contains(parsed) {
+				return "", "", true
+			}
+		}
+	}
+
+	apiKey := c.GetHeader(r.apiKeyHeader)
+	if apiKey != "" && r.exemptAPIKeys[apiKey] {
+		return "", "", true
+	}
+
+	if keyBy == rateLimitKeyUser || len(r.exemptUsers) > 0 {
+		if claims := bearerClaims(c); claims != nil {
+			if r.exemptUsers[claims.Username] {
+				return "", "", true
+			}
+			if keyBy == rateLimitKeyUser {
+				return rateLimitKeyUser, strconv.FormatUint(uint64(claims.UserID), 10), false
+			}
+		}
+	}
+
+	// 未校验的key不单独计数，否则每次换一个随机key就能得到新的计数桶
+	if (keyBy == rateLimitKeyUser || keyBy == rateLimitKeyAPIKey) && apiKey != "" && r.apiKeys[apiKey] {
+		// 不在Redis中保存明文API Key
+		sum := sha256.Sum256([]byte(apiKey))
+		return rateLimitKeyAPIKey, hex.EncodeToString(sum[:8]), false
+	}
+
+	return rateLimitKeyIP, ip, false
+}
+
+// bearerClaims 解析请求中的JWT，未携带或无效时返回nil
+func bearerClaims(c *gin.Context) *jwt.Claims {
+	parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return nil
+	}
+	claims, err := jwt.ParseToken(parts[1])
+	if err != nil {
+		return nil
+	}
+	return claims
+}
+
+// RateLimit 限流中间件，按 performance.rate_limit 的路由前缀策略和限流维度计数，配置热更新后立即生效
+func RateLimit(cfg config.RateLimitConfig) gin.HandlerFunc {
+	// 内存存储在配置重载间保留，避免计数被清空
+	memoryStore := memory.NewStore()
+	newStore := func(cfg config.RateLimitConfig) limiter.Store {
+		if cfg.Store == "redis" {
+			return ratelimit.NewRedisStore(redis.GetClient(), "ratelimit")
+		}
+		return memoryStore
+	}
+
+	var rules atomic.Pointer[rateLimitRules]
+	rules.Store(newRateLimitRules(cfg, newStore(cfg)))
+
+	config.OnChange(func(old, new *config.Config) {
+		rules.Store(newRateLimitRules(new.Performance.RateLimit, newStore(new.Performance.RateLimit)))
+	})
 
 	return func(c *gin.Context) {
-		// 使用客户端IP作为限流key
-		key := c.ClientIP()
+		r := rules.Load()
+		if r == nil {
+			c.Next()
+			return
+		}
 
-		context, err := instance.Get(c, key)
+		policy := r.match(c.Request.URL.Path)
+		if policy.disabled {
+			c.Next()
+			return
+		}
+
+		keyType, id, exempt := r.identify(c, policy.keyBy)
+		if exempt {
+			c.Next()
+			return
+		}
+		key := policy.name + ":" + keyType + ":" + id
+
+		context, err := policy.minute.Get(c, key)
 		if err != nil {
-			response.InternalServerError(c, errno.InternalServerError)
-			c.Abort()
+			// 存储不可用时放行，避免限流组件故障导致整体不可用
+			rateLimitErrorsTotal.WithLabelValues(policy.name).Inc()
+			logger.Error("限流计数失败，已放行请求", zap.Error(err), zap.String("policy", policy.name))
+			c.Next()
 			return
 		}
 
@@ -40,7 +260,25 @@ func RateLimit() gin.HandlerFunc {
 		c.Header("X-RateLimit-Remaining", strconv.FormatInt(context.Remaining, 10))
 		c.Header("X-RateLimit-Reset", strconv.FormatInt(context.Reset, 10))
 
+		window := "minute"
+		if !context.Reached && policy.second != nil {
+			burst, err := policy.second.Get(c, key+":burst")
+			if err != nil {
+				rateLimitErrorsTotal.WithLabelValues(policy.name).Inc()
+				logger.Error("限流计数失败，已放行请求", zap.Error(err), zap.String("policy", policy.name))
+			} else if burst.Reached {
+				context = burst
+				window = "second"
+			}
+		}
+
 		if context.Reached {
+			rateLimitRejectedTotal.WithLabelValues(policy.name, keyType, window).Inc()
+			retryAfter := context.Reset - time.Now().Unix()
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
 			response.ErrorWithCode(c, http.StatusTooManyRequests, errno.ErrLimitExceed)
 			c.Abort()
 			return
@@ -0,0 +1,188 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// redactMask 脱敏后的替换值
+const redactMask = "***"
+
+// redactor 编译后的脱敏规则
+type redactor struct {
+	// names 匹配任意层级的字段名（小写）
+	names map[string]bool
+	// paths 从根开始匹配的字段路径（小写）
+	paths [][]string
+	// headers 需要脱敏的请求头（规范化名称）
+	headers map[string]bool
+	// pattern JSON无法解析（如被截断）时按字段名替换值
+	pattern *regexp.Regexp
+	// xmlPattern 替换 <key>value</key> 元素的内容（XML、文本），自闭合元素不处理
+	xmlPattern *regexp.Regexp
+	// pairPattern 替换 key=value 形式的值（文本、日志行）
+	pairPattern *regexp.Regexp
+}
+
+// newRedactor 根据 log.http.redact_fields 和 redact_headers 生成脱敏规则
+func newRedactor(fields, headers []string) *redactor {
+	r := &redactor{
+		names:   make(map[string]bool),
+		headers: make(map[string]bool),
+	}
+
+	// 路径的最后一级同时用于文本兜底替换
+	var keys []string
+	for _, field := range fields {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" {
+			continue
+		}
+		if !strings.Contains(field, ".") {
+			r.names[field] = true
+			keys = append(keys, regexp.QuoteMeta(field))
+			continue
+		}
+		path := strings.Split(field, ".")
+		r.paths = append(r.paths, path)
+		if last := path[len(path)-1]; last != "*" {
+			keys = append(keys, regexp.QuoteMeta(last))
+		}
+	}
+	if len(keys) > 0 {
+		names := strings.Join(keys, "|")
+		r.pattern = regexp.MustCompile(`(?i)("(?:` + names + `)"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`)
+		r.xmlPattern = regexp.MustCompile(`(?i)(<(?:[\w.-]+:)?(?:` + names + `)(?:\s[^>]*[^/])?>)(<!\[CDATA\[.*?(?:\]\]>|$)|[^<]*)`)
+		r.pairPattern = regexp.MustCompile(`(?i)(\b(?:` + names + `)\s*=\s*)("[^"]*"?|'[^']*'?|[^&\s,;"'<]+)`)
+	}
+
+	for _, header := range headers {
+		r.headers[http.CanonicalHeaderKey(header)] = true
+	}
+	return r
+}
+
+// match 判断字段路径是否需要脱敏
+func (r *redactor) match(path []string) bool {
+	if r.names[path[len(path)-1]] {
+		return true
+	}
+
+	for _, pattern := range r.paths {
+		if len(pattern) != len(path) {
+			continue
+		}
+		matched := true
+		for i, segment := range pattern {
+			if segment != "*" && segment != path[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// body 脱敏请求/响应体，truncated 表示内容已被截断
+func (r *redactor) body(contentType string, body []byte, truncated bool) string {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+
+	switch {
+	case strings.Contains(mediaType, "json") && !truncated:
+		if redacted, ok := r.json(body); ok {
+			return string(redacted)
+		}
+	case mediaType == "application/x-www-form-urlencoded":
+		return r.query(string(body))
+	}
+
+	// 截断或无法解析的内容按字段名兜底替换
+	return r.text(string(body))
+}
+
+// json 解析JSON后按字段路径脱敏，解析失败时返回false
+func (r *redactor) json(body []byte) ([]byte, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, false
+	}
+
+	redacted, err := json.Marshal(r.walk(value, nil))
+	if err != nil {
+		return nil, false
+	}
+	return redacted, true
+}
+
+// walk 递归脱敏，数组元素不占路径层级
+func (r *redactor) walk(value interface{}, path []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			childPath := append(path[:len(path):len(path)], strings.ToLower(key))
+			if r.match(childPath) {
+				v[key] = redactMask
+				continue
+			}
+			v[key] = r.walk(child, childPath)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = r.walk(child, path)
+		}
+	}
+	return value
+}
+
+// query 脱敏查询字符串和表单，保持参数顺序
+func (r *redactor) query(raw string) string {
+	if raw == "" || len(r.names) == 0 {
+		return raw
+	}
+
+	pairs := strings.Split(raw, "&")
+	for i, pair := range pairs {
+		rawKey, _, _ := strings.Cut(pair, "=")
+		key := rawKey
+		if unescaped, err := url.QueryUnescape(rawKey); err == nil {
+			key = unescaped
+		}
+		if r.names[strings.ToLower(key)] {
+			pairs[i] = rawKey + "=" + redactMask
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// text 按字段名替换 "key": value、<key>value</key> 和 key=value 形式的值
+func (r *redactor) text(s string) string {
+	if r.pattern == nil {
+		return s
+	}
+	s = r.pattern.ReplaceAllString(s, `${1}"`+redactMask+`"`)
+	s = r.xmlPattern.ReplaceAllString(s, `${1}`+redactMask)
+	return r.pairPattern.ReplaceAllString(s, `${1}`+redactMask)
+}
+
+// header 返回脱敏后的请求头
+func (r *redactor) header(h http.Header) map[string]string {
+	result := make(map[string]string, len(h))
+	for key, values := range h {
+		if r.headers[key] {
+			result[key] = redactMask
+			continue
+		}
+		result[key] = strings.Join(values, ", ")
+	}
+	return result
+}
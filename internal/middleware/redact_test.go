@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRedactorBody(t *testing.T) {
+	r := newRedactor([]string{"password", "token", "card.number", "items.*.secret"}, nil)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		truncated   bool
+		want        string
+	}{
+		{
+			name:        "JSON任意层级字段名",
+			contentType: "application/json",
+			body:        `{"user":"bob","Password":"p1","profile":{"token":"t1"}}`,
+			want:        `{"Password":"***","profile":{"token":"***"},"user":"bob"}`,
+		},
+		{
+			name:        "JSON字段路径",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"card":{"number":"6222","holder":"bob"},"number":"1"}`,
+			want:        `{"card":{"holder":"bob","number":"***"},"number":"1"}`,
+		},
+		{
+			name:        "路径通配且数组元素不占层级",
+			contentType: "application/json",
+			body:        `{"items":[{"a":{"secret":"s1"}},{"a":{"secret":"s2","id":1}}]}`,
+			want:        `{"items":[{"a":{"secret":"***"}},{"a":{"id":1,"secret":"***"}}]}`,
+		},
+		{
+			name:        "截断的JSON按文本替换",
+			contentType: "application/json",
+			body:        `{"user":"bob","password":"p1","token":"abc`,
+			truncated:   true,
+			want:        `{"user":"bob","password":"***","token":"***"`,
+		},
+		{
+			name:        "表单",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "user=bob&password=p1&Token=t1&passwords=keep",
+			want:        "user=bob&password=***&Token=***&passwords=keep",
+		},
+		{
+			name:        "XML元素",
+			contentType: "application/xml",
+			body:        `<req><user>bob</user><password>p1</password><ns:token attr="1">t1</ns:token><password_hint>h</password_hint></req>`,
+			want:        `<req><user>bob</user><password>***</password><ns:token attr="1">***</ns:token><password_hint>h</password_hint></req>`,
+		},
+		{
+			name:        "XML的CDATA与自闭合元素",
+			contentType: "text/xml",
+			body:        `<token><![CDATA[a<b]]></token><password/><password />`,
+			want:        `<token>***</token><password/><password />`,
+		},
+		{
+			name:        "文本中的键值对",
+			contentType: "text/plain",
+			body:        `login user=bob password=p1&token="t 1" passwords=keep`,
+			want:        `login user=bob password=***&token=*** passwords=keep`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.body(tt.contentType, []byte(tt.body), tt.truncated); got != tt.want {
+				t.Errorf("body() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactorWithoutFields(t *testing.T) {
+	r := newRedactor(nil, nil)
+	body := `{"password":"p1"} password=p1`
+	if got := r.body("text/plain", []byte(body), false); got != body {
+		t.Errorf("body() = %s, want unchanged", got)
+	}
+	if got := r.query("password=p1"); got != "password=p1" {
+		t.Errorf("query() = %s, want unchanged", got)
+	}
+}
+
+func TestRedactorHeader(t *testing.T) {
+	r := newRedactor(nil, []string{"authorization", "X-Api-Key"})
+
+	got := r.header(http.Header{
+		"Authorization": {"Bearer abc"},
+		"X-Api-Key":     {"key"},
+		"Accept":        {"text/html", "application/json"},
+	})
+
+	want := map[string]string{
+		"Authorization": redactMask,
+		"X-Api-Key":     redactMask,
+		"Accept":        "text/html, application/json",
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("header[%s] = %q, want %q", key, got[key], value)
+		}
+	}
+}
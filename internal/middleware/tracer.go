@@ -1,59 +1,66 @@
 package middleware
 
 import (
+	"fmt"
+	"net/http"
+
 	"ocean-marketing/internal/pkg/tracer"
 
 	"github.com/gin-gonic/gin"
-	"github.com/opentracing/opentracing-go"
-	"github.com/opentracing/opentracing-go/ext"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// Tracer 链路追踪中间件
+// Tracer 链路追踪中间件，从请求头提取W3C trace上下文和baggage，为每个请求创建服务端span
 func Tracer() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 从请求头中提取span上下文
-		var span opentracing.Span
-		wireContext, err := opentracing.GlobalTracer().Extract(
-			opentracing.HTTPHeaders,
-			opentracing.HTTPHeadersCarrier(c.Request.Header),
-		)
+		// 从请求头中提取上游的trace上下文
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
 
-		if err != nil {
-			// 创建新的span
-			span = tracer.StartSpan(c.Request.URL.Path)
-		} else {
-			// 基于已有上下文创建span
-			span = tracer.StartSpanFromContext(wireContext, c.Request.URL.Path)
+		// 使用路由模板命名，避免路径参数导致span名称过多
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
 		}
 
-		defer span.Finish()
-
-		// 设置标签
-		ext.HTTPMethod.Set(span, c.Request.Method)
-		ext.HTTPUrl.Set(span, c.Request.URL.String())
-		ext.Component.Set(span, "gin-server")
+		ctx, span := tracer.StartSpan(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+				semconv.ClientAddress(c.ClientIP()),
+				semconv.UserAgentOriginal(c.Request.UserAgent()),
+			),
+		)
+		defer span.End()
 
-		// 将span上下文注入到请求中
+		// 后续处理通过 c.Request.Context() 获取span
+		c.Request = c.Request.WithContext(ctx)
 		c.Set("span", span)
 
 		// 处理请求
 		c.Next()
 
 		// 设置状态码
-		ext.HTTPStatusCode.Set(span, uint16(c.Writer.Status()))
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
 
 		// 如果有错误，标记span
 		if len(c.Errors) > 0 {
-			ext.Error.Set(span, true)
-			span.SetTag("error.message", c.Errors.String())
+			span.SetAttributes(attribute.String("error.message", c.Errors.String()))
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", status))
 		}
 	}
 }
 
 // GetSpan 从gin上下文中获取span
-func GetSpan(c *gin.Context) opentracing.Span {
-	if span, exists := c.Get("span"); exists {
-		return span.(opentracing.Span)
-	}
-	return nil
+func GetSpan(c *gin.Context) trace.Span {
+	return trace.SpanFromContext(c.Request.Context())
 }
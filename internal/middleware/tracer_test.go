@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ocean-marketing/internal/config"
+	"ocean-marketing/internal/pkg/logger"
+	"ocean-marketing/internal/pkg/tracer"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "middleware-test")
+	if err != nil {
+		panic(err)
+	}
+	logger.Init(config.LogConfig{Level: "error", Format: "json", OutputPath: filepath.Join(dir, "app.log"), MaxSize: 1})
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestTracer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tracer.Init(config.TracerConfig{
+		Enabled:     true,
+		ServiceName: "ocean-marketing-test",
+		Exporter:    tracer.ExporterMemory,
+		SampleRate:  1,
+	})
+	exporter := tracer.MemoryExporter()
+	if exporter == nil {
+		t.Fatal("memory exporter not initialized")
+	}
+
+	r := gin.New()
+	r.Use(Tracer())
+	r.GET("/users/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/error", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+
+	const parentTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	tests := []struct {
+		name        string
+		path        string
+		traceparent string
+		wantName    string
+		wantStatus  int
+		wantError   bool
+	}{
+		{name: "按路由模板命名", path: "/users/42", wantName: "GET /users/:id", wantStatus: http.StatusOK},
+		{name: "继承上游trace", path: "/users/42", traceparent: "00-" + parentTraceID + "-00f067aa0ba902b7-01",
+			wantName: "GET /users/:id", wantStatus: http.StatusOK},
+		{name: "5xx标记为错误", path: "/error", wantName: "GET /error", wantStatus: http.StatusInternalServerError, wantError: true},
+		{name: "未匹配的路由", path: "/not-found", wantName: "GET " + unmatchedRoute, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter.Reset()
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("spans = %d, want 1", len(spans))
+			}
+			span := spans[0]
+
+			if span.Name != tt.wantName {
+				t.Errorf("name = %q, want %q", span.Name, tt.wantName)
+			}
+			if span.SpanKind != trace.SpanKindServer {
+				t.Errorf("kind = %v, want server", span.SpanKind)
+			}
+			if got := attributeValue(span.Attributes, semconv.HTTPResponseStatusCodeKey); got.AsInt64() != int64(tt.wantStatus) {
+				t.Errorf("status attribute = %v, want %d", got.Emit(), tt.wantStatus)
+			}
+			if got := attributeValue(span.Attributes, semconv.URLPathKey); got.AsString() != tt.path {
+				t.Errorf("url.path = %q, want %q", got.AsString(), tt.path)
+			}
+			if tt.traceparent != "" {
+				if got := span.SpanContext.TraceID().String(); got != parentTraceID {
+					t.Errorf("trace id = %s, want %s", got, parentTraceID)
+				}
+				if !span.Parent.IsRemote() {
+					t.Errorf("parent should be remote")
+				}
+			}
+			if (span.Status.Code == codes.Error) != tt.wantError {
+				t.Errorf("status = %v, want error %v", span.Status.Code, tt.wantError)
+			}
+		})
+	}
+}
+
+// attributeValue 查找span属性
+func attributeValue(attrs []attribute.KeyValue, key attribute.Key) attribute.Value {
+	for _, kv := range attrs {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"strconv"
+	"time"
+)
+
+// 示例领域事件类型
+const (
+	ExampleAggregate    = "example"
+	ExampleCreatedEvent = "example.created"
+	ExampleUpdatedEvent = "example.updated"
+	ExampleDeletedEvent = "example.deleted"
+)
+
+// FieldChange 字段变更前后的值
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// ExampleCreated 示例已创建
+type ExampleCreated struct {
+	ID          uint      `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Status      int       `json:"status"`
+	Sort        int       `json:"sort"`
+	CreatedBy   string    `json:"created_by"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+// EventType 事件类型
+func (ExampleCreated) EventType() string { return ExampleCreatedEvent }
+
+// AggregateType 聚合类型
+func (ExampleCreated) AggregateType() string { return ExampleAggregate }
+
+// AggregateID 聚合ID
+func (e ExampleCreated) AggregateID() string { return strconv.FormatUint(uint64(e.ID), 10) }
+
+// ExampleUpdated 示例已更新，Changes只包含实际发生变化的字段
+type ExampleUpdated struct {
+	ID         uint                   `json:"id"`
+	Changes    map[string]FieldChange `json:"changes"`
+	UpdatedBy  string                 `json:"updated_by"`
+	OccurredAt time.Time              `json:"occurred_at"`
+}
+
+// EventType 事件类型
+func (ExampleUpdated) EventType() string { return ExampleUpdatedEvent }
+
+// AggregateType 聚合类型
+func (ExampleUpdated) AggregateType() string { return ExampleAggregate }
+
+// AggregateID 聚合ID
+func (e ExampleUpdated) AggregateID() string { return strconv.FormatUint(uint64(e.ID), 10) }
+
+// ExampleDeleted 示例已删除
+type ExampleDeleted struct {
+	ID         uint      `json:"id"`
+	DeletedBy  string    `json:"deleted_by"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// EventType 事件类型
+func (ExampleDeleted) EventType() string { return ExampleDeletedEvent }
+
+// AggregateType 聚合类型
+func (ExampleDeleted) AggregateType() string { return ExampleAggregate }
+
+// AggregateID 聚合ID
+func (e ExampleDeleted) AggregateID() string { return strconv.FormatUint(uint64(e.ID), 10) }
+
+// NewExampleCreated 根据示例创建事件
+func NewExampleCreated(example *Example) ExampleCreated {
+	return ExampleCreated{
+		ID:          example.ID,
+		Title:       example.Title,
+		Description: example.Description,
+		Status:      example.Status,
+		Sort:        example.Sort,
+		CreatedBy:   example.CreatedBy,
+		OccurredAt:  time.Now(),
+	}
+}
+
+// DiffExample 对比更新前后的示例，返回变化的字段
+func DiffExample(before, after *Example) map[string]FieldChange {
+	changes := make(map[string]FieldChange)
+	if before.Title != after.Title {
+		changes["title"] = FieldChange{Old: before.Title, New: after.Title}
+	}
+	if before.Description != after.Description {
+		changes["description"] = FieldChange{Old: before.Description, New: after.Description}
+	}
+	if before.Status != after.Status {
+		changes["status"] = FieldChange{Old: before.Status, New: after.Status}
+	}
+	if before.Sort != after.Sort {
+		changes["sort"] = FieldChange{Old: before.Sort, New: after.Sort}
+	}
+	return changes
+}
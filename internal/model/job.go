@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+)
+
+// 任务状态
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+)
+
+// Job 后台任务
+type Job struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Name        string  `json:"name" gorm:"size:128;not null;index;comment:任务处理器名称"`
+	Payload     string  `json:"payload" gorm:"type:text;comment:任务参数JSON"`
+	Priority    int     `json:"priority" gorm:"default:0;comment:优先级，越大越先执行"`
+	UniqueKey   *string `json:"unique_key" gorm:"size:191;uniqueIndex;comment:唯一键，任务结束后释放"`
+	Status      string  `json:"status" gorm:"size:16;not null;index:idx_jobs_claim,priority:1;comment:状态"`
+	Attempts    int     `json:"attempts" gorm:"default:0;comment:已执行次数"`
+	MaxAttempts int     `json:"max_attempts" gorm:"default:3;comment:最大执行次数"`
+	LastError   string  `json:"last_error" gorm:"type:text;comment:最近一次错误"`
+
+	RunAt       time.Time  `json:"run_at" gorm:"not null;index:idx_jobs_claim,priority:2;comment:下次可执行时间"`
+	LockedBy    string     `json:"locked_by" gorm:"size:64;comment:执行中的worker"`
+	LockedUntil *time.Time `json:"locked_until" gorm:"comment:执行租约到期时间"`
+	StartedAt   *time.Time `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+}
+
+// TableName 指定表名
+func (Job) TableName() string {
+	return "jobs"
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+)
+
+// Outbox消息状态
+const (
+	OutboxStatusPending   = 0
+	OutboxStatusDelivered = 1
+	OutboxStatusFailed    = 2
+)
+
+// OutboxMessage 事务发件箱消息，与业务数据在同一事务中写入
+type OutboxMessage struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	MessageID     string     `json:"message_id" gorm:"size:64;not null;uniqueIndex;comment:消息ID，投递重试时保持不变"`
+	AggregateType string     `json:"aggregate_type" gorm:"size:64;not null;index:idx_outbox_aggregate;comment:聚合类型"`
+	AggregateID   string     `json:"aggregate_id" gorm:"size:64;not null;index:idx_outbox_aggregate;comment:聚合ID"`
+	EventType     string     `json:"event_type" gorm:"size:128;not null;comment:事件类型"`
+	Exchange      string     `json:"exchange" gorm:"size:128;comment:交换器，为空时使用默认交换器"`
+	RoutingKey    string     `json:"routing_key" gorm:"size:128;comment:路由键，为空时使用事件类型"`
+	Payload       string     `json:"payload" gorm:"type:text;comment:消息数据JSON"`
+	TraceContext  string     `json:"trace_context" gorm:"size:512;comment:写入时的链路上下文JSON，投递时恢复"`
+	Status        int        `json:"status" gorm:"default:0;index:idx_outbox_status;comment:状态 0待投递 1已投递 2失败"`
+	Attempts      int        `json:"attempts" gorm:"default:0;comment:投递次数"`
+	LastError     string     `json:"last_error" gorm:"size:512;comment:最近一次投递错误"`
+	DeliveredAt   *time.Time `json:"delivered_at" gorm:"index;comment:投递时间"`
+}
+
+// TableName 指定表名
+func (OutboxMessage) TableName() string {
+	return "outbox_messages"
+}
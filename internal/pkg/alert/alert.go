@@ -0,0 +1,325 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"ocean-marketing/internal/config"
+	"ocean-marketing/internal/pkg/logger"
+	"ocean-marketing/internal/pkg/version"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// logModule 告警日志的模块名
+const logModule = "alert"
+
+// Severity 告警级别
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// 告警丢弃原因
+const (
+	dropQueueFull = "queue_full"
+	dropSilenced  = "silenced"
+	dropClosed    = "closed"
+)
+
+var (
+	// 各通道发送告警的次数，result 为 success 或 failure
+	alertsSentTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "alerts_sent_total",
+			Help: "Total number of alerts sent by channel and result",
+		},
+		[]string{"channel", "result"},
+	)
+
+	// 未发送而丢弃的告警数
+	alertsDroppedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "alerts_dropped_total",
+			Help: "Total number of alerts dropped before sending",
+		},
+		[]string{"reason"},
+	)
+)
+
+// Field 告警附加信息
+type Field struct {
+	Key   string
+	Value string
+}
+
+// F 创建告警附加信息
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: fmt.Sprint(value)}
+}
+
+// Alert 告警内容
+type Alert struct {
+	Severity Severity
+	// Title 告警标题，级别和标题相同的告警在静默时间内只发送一次，不要包含ID、时间等每次都不同的内容
+	Title string
+	// Content 详细内容，如错误信息和堆栈
+	Content string
+	// Fields 附加信息，按顺序展示
+	Fields []Field
+	// Time 发生时间，为空时取调用Send的时间
+	Time time.Time
+}
+
+// Notifier 告警通道
+type Notifier interface {
+	// Name 通道名，与 alert.routes 中的名称一致
+	Name() string
+	// Notify 发送告警
+	Notify(ctx context.Context, a Alert) error
+}
+
+// message 待发送的告警，ctx携带trace上下文但不会被取消
+type message struct {
+	ctx   context.Context
+	alert Alert
+}
+
+// dispatcher 按级别路由告警，后台逐条发送
+type dispatcher struct {
+	routes  map[Severity][]Notifier
+	timeout time.Duration
+	silence time.Duration
+
+	mu       sync.Mutex
+	lastSent map[string]time.Time
+	closed   bool
+
+	queue chan message
+	done  chan struct{}
+}
+
+var std *dispatcher
+
+// Init 初始化告警，按 alert.routes 为每个级别挑选已配置的通道
+func Init(cfg config.AlertConfig, emailCfg config.EmailConfig) {
+	notifiers := make(map[string]Notifier)
+	if cfg.Feishu.WebhookURL != "" {
+		notifiers["feishu"] = NewFeishu(cfg.Feishu)
+	}
+	if cfg.DingTalk.WebhookURL != "" {
+		notifiers["dingtalk"] = NewDingTalk(cfg.DingTalk)
+	}
+	if cfg.WeCom.WebhookURL != "" {
+		notifiers["wecom"] = NewWeCom(cfg.WeCom)
+	}
+	if cfg.Slack.WebhookURL != "" {
+		notifiers["slack"] = NewSlack(cfg.Slack)
+	}
+	if len(cfg.Email.To) > 0 {
+		notifiers["email"] = NewEmail(emailCfg, cfg.Email.To)
+	}
+
+	routes := make(map[Severity][]Notifier)
+	for severity, channels := range cfg.Routes {
+		for _, channel := range channels {
+			if n, ok := notifiers[channel]; ok {
+				routes[Severity(severity)] = append(routes[Severity(severity)], n)
+			}
+		}
+	}
+
+	d := &dispatcher{
+		routes:   routes,
+		timeout:  time.Duration(cfg.Timeout) * time.Second,
+		silence:  time.Duration(cfg.SilenceWindow) * time.Second,
+		lastSent: make(map[string]time.Time),
+		queue:    make(chan message, cfg.QueueSize),
+		done:     make(chan struct{}),
+	}
+	go d.run()
+	std = d
+
+	names := make([]string, 0, len(notifiers))
+	for name := range notifiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	logger.Named(logModule).Info("告警初始化完成", zap.Strings("channels", names))
+}
+
+// Send 异步发送告警，立即返回；未初始化、没有可用通道、处于静默期或队列已满时丢弃
+func Send(ctx context.Context, a Alert) {
+	if std == nil {
+		return
+	}
+	std.send(ctx, a)
+}
+
+// Close 停止接收新告警，等待队列中的告警发送完成或ctx到期
+func Close(ctx context.Context) error {
+	if std == nil {
+		return nil
+	}
+	return std.close(ctx)
+}
+
+func (d *dispatcher) send(ctx context.Context, a Alert) {
+	if len(d.routes[a.Severity]) == 0 {
+		return
+	}
+	if a.Time.IsZero() {
+		a.Time = time.Now()
+	}
+	a.Fields = append(a.Fields[:len(a.Fields):len(a.Fields)], contextFields(ctx)...)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		alertsDroppedTotal.WithLabelValues(dropClosed).Inc()
+		return
+	}
+	if d.silenced(a) {
+		alertsDroppedTotal.WithLabelValues(dropSilenced).Inc()
+		return
+	}
+
+	select {
+	case d.queue <- message{ctx: context.WithoutCancel(ctx), alert: a}:
+	default:
+		// 未发送出去，不占用静默期
+		delete(d.lastSent, silenceKey(a))
+		alertsDroppedTotal.WithLabelValues(dropQueueFull).Inc()
+		logger.Named(logModule).Warn("告警队列已满，丢弃告警", zap.String("title", a.Title))
+	}
+}
+
+// silenced 相同告警是否仍在静默期，不在时记录本次发送时间；调用方需持有锁
+func (d *dispatcher) silenced(a Alert) bool {
+	if d.silence <= 0 {
+		return false
+	}
+
+	now := time.Now()
+	for key, at := range d.lastSent {
+		if now.Sub(at) >= d.silence {
+			delete(d.lastSent, key)
+		}
+	}
+
+	key := silenceKey(a)
+	if _, ok := d.lastSent[key]; ok {
+		return true
+	}
+	d.lastSent[key] = now
+	return false
+}
+
+// silenceKey 判断相同告警的键
+func silenceKey(a Alert) string {
+	return string(a.Severity) + "|" + a.Title
+}
+
+func (d *dispatcher) run() {
+	defer close(d.done)
+
+	for m := range d.queue {
+		for _, n := range d.routes[m.alert.Severity] {
+			d.notify(m.ctx, n, m.alert)
+		}
+	}
+}
+
+// notify 通过单个通道发送，失败只记录日志，不影响其他通道
+func (d *dispatcher) notify(ctx context.Context, n Notifier, a Alert) {
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+
+	if err := n.Notify(ctx, a); err != nil {
+		alertsSentTotal.WithLabelValues(n.Name(), "failure").Inc()
+		logger.Named(logModule).Error("发送告警失败",
+			zap.Error(err),
+			zap.String("channel", n.Name()),
+			zap.String("title", a.Title))
+		return
+	}
+	alertsSentTotal.WithLabelValues(n.Name(), "success").Inc()
+}
+
+func (d *dispatcher) close(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// contextFields 请求ID和trace ID，便于从告警查到对应的日志和链路
+func contextFields(ctx context.Context) []Field {
+	var fields []Field
+	if requestID := logger.RequestIDFromContext(ctx); requestID != "" {
+		fields = append(fields, F("请求ID", requestID))
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		fields = append(fields, F("TraceID", spanContext.TraceID().String()))
+	}
+	return fields
+}
+
+// hostname 告警中展示的主机名
+var hostname, _ = os.Hostname()
+
+// header 告警标题前缀，如 [CRITICAL]
+func header(a Alert) string {
+	return "[" + strings.ToUpper(string(a.Severity)) + "] " + a.Title
+}
+
+// details 告警的公共信息和附加信息，按顺序展示
+func details(a Alert) []Field {
+	fields := []Field{{Key: "时间", Value: a.Time.Format("2006-01-02 15:04:05")}}
+	if env := config.Env(); env != "" {
+		fields = append(fields, Field{Key: "环境", Value: env})
+	}
+	fields = append(fields,
+		Field{Key: "主机", Value: hostname},
+		Field{Key: "版本", Value: version.Version},
+	)
+	return append(fields, a.Fields...)
+}
+
+// maxContentLength 告警详细内容的最大长度（字节），机器人消息有长度限制
+const maxContentLength = 3000
+
+// truncate 截断过长的内容
+func truncate(s string) string {
+	if len(s) <= maxContentLength {
+		return s
+	}
+	// 避免截断在UTF-8字符中间
+	cut := maxContentLength
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "\n...(已截断)"
+}
@@ -0,0 +1,79 @@
+package alert
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"ocean-marketing/internal/config"
+)
+
+// DingTalk 钉钉机器人，发送markdown消息
+type DingTalk struct {
+	cfg config.DingTalkConfig
+}
+
+// NewDingTalk 创建钉钉通道
+func NewDingTalk(cfg config.DingTalkConfig) *DingTalk {
+	return &DingTalk{cfg: cfg}
+}
+
+// Name 通道名
+func (d *DingTalk) Name() string {
+	return "dingtalk"
+}
+
+// Notify 发送markdown消息，配置了加签密钥时在地址上附带签名
+func (d *DingTalk) Notify(ctx context.Context, a Alert) error {
+	webhookURL := d.cfg.WebhookURL
+	if d.cfg.Secret != "" {
+		u, err := url.Parse(webhookURL)
+		if err != nil {
+			return err
+		}
+		timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+		query := u.Query()
+		query.Set("timestamp", timestamp)
+		query.Set("sign", dingTalkSign(timestamp, d.cfg.Secret))
+		u.RawQuery = query.Encode()
+		webhookURL = u.String()
+	}
+
+	body := map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"title": header(a),
+			"text":  "### " + header(a) + "\n\n" + markdownLines(markdown(a, doubleStar)),
+		},
+	}
+
+	var result struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err := postJSON(ctx, webhookURL, body, &result); err != nil {
+		return err
+	}
+	if result.ErrCode != 0 {
+		return fmt.Errorf("alert: dingtalk errcode %d: %s", result.ErrCode, result.ErrMsg)
+	}
+	return nil
+}
+
+// dingTalkSign 钉钉加签：以secret为密钥对 timestamp+"\n"+secret 做HmacSHA256后Base64
+func dingTalkSign(timestamp, secret string) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(timestamp + "\n" + secret))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// markdownLines 钉钉markdown中单个换行不会换行，改为空行分隔
+func markdownLines(s string) string {
+	return strings.ReplaceAll(s, "\n", "\n\n")
+}
@@ -0,0 +1,44 @@
+package alert
+
+import (
+	"context"
+	"strings"
+
+	"ocean-marketing/internal/config"
+	"ocean-marketing/pkg/email"
+)
+
+// Email 通过 pkg/email 发送纯文本告警邮件
+type Email struct {
+	client *email.Client
+	to     []string
+}
+
+// NewEmail 创建邮件通道
+func NewEmail(cfg config.EmailConfig, to []string) *Email {
+	return &Email{client: email.NewClient(cfg), to: to}
+}
+
+// Name 通道名
+func (e *Email) Name() string {
+	return "email"
+}
+
+// Notify 发送邮件，SMTP发送不支持ctx，在goroutine中发送并在ctx结束时返回，
+// 避免卡住的SMTP连接阻塞后续告警；超时后发送仍在后台继续，直到连接断开
+func (e *Email) Notify(ctx context.Context, a Alert) error {
+	plain := func(s string) string { return s }
+	body := strings.TrimRight(markdown(a, plain), "\n")
+
+	done := make(chan error, 1)
+	go func() {
+		done <- e.client.SendPlainTextEmail(e.to, header(a), body)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
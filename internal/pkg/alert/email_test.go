@@ -0,0 +1,59 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ocean-marketing/internal/config"
+	"ocean-marketing/internal/pkg/logger"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "alert-test")
+	if err != nil {
+		panic(err)
+	}
+	logger.Init(config.LogConfig{Level: "error", Format: "json", OutputPath: filepath.Join(dir, "app.log"), MaxSize: 1})
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestEmailNotifyHonorsContext(t *testing.T) {
+	// 只接受连接不发送SMTP问候，模拟卡住的服务器
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	e := NewEmail(config.EmailConfig{Host: "127.0.0.1", Port: addr.Port, From: "alert@example.com"}, []string{"ops@example.com"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = e.Notify(ctx, Alert{Severity: SeverityWarning, Title: "test", Content: "hung smtp"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Notify() = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Notify returned after %v, want about 100ms", elapsed)
+	}
+}
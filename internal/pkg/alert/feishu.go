@@ -0,0 +1,79 @@
+package alert
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"time"
+
+	"ocean-marketing/internal/config"
+)
+
+// feishuColors 各级别卡片标题的颜色
+var feishuColors = map[Severity]string{
+	SeverityInfo:     "blue",
+	SeverityWarning:  "orange",
+	SeverityCritical: "red",
+}
+
+// Feishu 飞书机器人，发送卡片消息
+type Feishu struct {
+	cfg config.FeishuConfig
+}
+
+// NewFeishu 创建飞书通道
+func NewFeishu(cfg config.FeishuConfig) *Feishu {
+	return &Feishu{cfg: cfg}
+}
+
+// Name 通道名
+func (f *Feishu) Name() string {
+	return "feishu"
+}
+
+// Notify 发送卡片消息，配置了签名密钥时附带签名
+func (f *Feishu) Notify(ctx context.Context, a Alert) error {
+	body := map[string]interface{}{
+		"msg_type": "interactive",
+		"card": map[string]interface{}{
+			"config": map[string]interface{}{"wide_screen_mode": true},
+			"header": map[string]interface{}{
+				"template": feishuColors[a.Severity],
+				"title":    map[string]string{"tag": "plain_text", "content": header(a)},
+			},
+			"elements": []interface{}{
+				map[string]interface{}{
+					"tag":  "div",
+					"text": map[string]string{"tag": "lark_md", "content": markdown(a, doubleStar)},
+				},
+			},
+		},
+	}
+
+	if f.cfg.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		body["timestamp"] = timestamp
+		body["sign"] = feishuSign(timestamp, f.cfg.Secret)
+	}
+
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := postJSON(ctx, f.cfg.WebhookURL, body, &result); err != nil {
+		return err
+	}
+	if result.Code != 0 {
+		return fmt.Errorf("alert: feishu code %d: %s", result.Code, result.Msg)
+	}
+	return nil
+}
+
+// feishuSign 飞书签名：以 timestamp+"\n"+secret 为密钥对空串做HmacSHA256后Base64
+func feishuSign(timestamp, secret string) string {
+	h := hmac.New(sha256.New, []byte(timestamp+"\n"+secret))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,117 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ocean-marketing/internal/config"
+)
+
+func TestFeishuSign(t *testing.T) {
+	// 期望值按飞书文档的算法独立计算：HmacSHA256(key=timestamp+"\n"+secret, message="") 后Base64
+	tests := []struct {
+		timestamp string
+		secret    string
+		want      string
+	}{
+		{timestamp: "1599360473", secret: "demo", want: "l1N0gAcBjdwBvGm1xMjOF0XSyaLRpR7tuO5dHfhAYc8="},
+		{timestamp: "1700000000", secret: "SECabc123", want: "UqhI0v4zAkSwI4hNYBuHQvnrAqshA0UaeBGHCUMPX70="},
+		{timestamp: "0", secret: "", want: "53Dh/MqIJzmbXR1ky1eoAPGAmY2mY/DJucsfzM60KVk="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.timestamp, func(t *testing.T) {
+			if got := feishuSign(tt.timestamp, tt.secret); got != tt.want {
+				t.Errorf("feishuSign(%q, %q) = %s, want %s", tt.timestamp, tt.secret, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDingTalkSign(t *testing.T) {
+	// 期望值按钉钉文档的算法独立计算：HmacSHA256(key=secret, message=timestamp+"\n"+secret) 后Base64
+	tests := []struct {
+		timestamp string
+		secret    string
+		want      string
+	}{
+		{timestamp: "1700000000000", secret: "SEC000000", want: "A+KC3I4p0mgxsFnN5H+cnrVpt7UWSBbevMlmsBlxam4="},
+		{timestamp: "1577262236757", secret: "SECd9c1ef1a", want: "CJ0pPD+e0GszRt0+/+GCTEH375fdeRgiXF/Y4BQjvZ8="},
+		{timestamp: "1", secret: "", want: "ZM8lDIq9Q7iNhtNykOhyuArHS7zmcwHOPtPaxF7ETwo="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.timestamp, func(t *testing.T) {
+			if got := dingTalkSign(tt.timestamp, tt.secret); got != tt.want {
+				t.Errorf("dingTalkSign(%q, %q) = %s, want %s", tt.timestamp, tt.secret, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFeishuNotifySigned(t *testing.T) {
+	tests := []struct {
+		name     string
+		secret   string
+		wantSign bool
+	}{
+		{name: "配置密钥时附带签名", secret: "SECabc123", wantSign: true},
+		{name: "未配置密钥时不签名", secret: "", wantSign: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]interface{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("decode body: %v", err)
+				}
+				_, _ = w.Write([]byte(`{"code":0,"msg":"success"}`))
+			}))
+			defer server.Close()
+
+			feishu := NewFeishu(config.FeishuConfig{WebhookURL: server.URL, Secret: tt.secret})
+			if err := feishu.Notify(context.Background(), Alert{Severity: SeverityCritical, Title: "服务异常"}); err != nil {
+				t.Fatalf("Notify: %v", err)
+			}
+
+			timestamp, _ := body["timestamp"].(string)
+			sign, _ := body["sign"].(string)
+			if !tt.wantSign {
+				if timestamp != "" || sign != "" {
+					t.Errorf("timestamp = %q, sign = %q, want none", timestamp, sign)
+				}
+				return
+			}
+			if timestamp == "" || sign != feishuSign(timestamp, tt.secret) {
+				t.Errorf("timestamp = %q, sign = %q, want valid signature", timestamp, sign)
+			}
+		})
+	}
+}
+
+func TestDingTalkNotifySigned(t *testing.T) {
+	var query map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	dingTalk := NewDingTalk(config.DingTalkConfig{WebhookURL: server.URL + "/robot/send?access_token=abc", Secret: "SEC000000"})
+	if err := dingTalk.Notify(context.Background(), Alert{Severity: SeverityWarning, Title: "任务失败"}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	if got := query["access_token"]; len(got) != 1 || got[0] != "abc" {
+		t.Errorf("access_token = %v, want abc", got)
+	}
+	timestamp := query["timestamp"]
+	sign := query["sign"]
+	if len(timestamp) != 1 || len(sign) != 1 || sign[0] != dingTalkSign(timestamp[0], "SEC000000") {
+		t.Errorf("timestamp = %v, sign = %v, want valid signature", timestamp, sign)
+	}
+}
@@ -0,0 +1,37 @@
+package alert
+
+import (
+	"context"
+
+	"ocean-marketing/internal/config"
+)
+
+// slackEmojis 各级别标题前的图标
+var slackEmojis = map[Severity]string{
+	SeverityInfo:     ":information_source:",
+	SeverityWarning:  ":warning:",
+	SeverityCritical: ":rotating_light:",
+}
+
+// Slack Slack Incoming Webhook，发送mrkdwn文本
+type Slack struct {
+	cfg config.WebhookConfig
+}
+
+// NewSlack 创建Slack通道
+func NewSlack(cfg config.WebhookConfig) *Slack {
+	return &Slack{cfg: cfg}
+}
+
+// Name 通道名
+func (s *Slack) Name() string {
+	return "slack"
+}
+
+// Notify 发送消息，Slack成功时返回纯文本 ok，只按HTTP状态码判断
+func (s *Slack) Notify(ctx context.Context, a Alert) error {
+	text := slackEmojis[a.Severity] + " *" + header(a) + "*\n" +
+		markdown(a, func(s string) string { return "*" + s + "*" })
+
+	return postJSON(ctx, s.cfg.WebhookURL, map[string]string{"text": text}, nil)
+}
@@ -0,0 +1,70 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"ocean-marketing/internal/pkg/tracer"
+)
+
+// httpClient 调用机器人webhook的HTTP客户端，超时由调用方的ctx控制
+var httpClient = tracer.NewHTTPClient(30 * time.Second)
+
+// postJSON 以JSON发送请求，HTTP状态码不是2xx时返回错误；result不为nil时解析响应
+func postJSON(ctx context.Context, url string, body, result interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("alert: status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(respBody, result); err != nil {
+			return fmt.Errorf("alert: decode response: %w", err)
+		}
+	}
+	return nil
+}
+
+// markdown 把告警渲染为markdown，bold为各平台的加粗写法
+func markdown(a Alert, bold func(s string) string) string {
+	var b strings.Builder
+	for _, f := range details(a) {
+		b.WriteString(bold(f.Key + ":"))
+		b.WriteString(" ")
+		b.WriteString(f.Value)
+		b.WriteString("\n")
+	}
+	if a.Content != "" {
+		b.WriteString("\n")
+		b.WriteString(truncate(a.Content))
+	}
+	return b.String()
+}
+
+// doubleStar 飞书、钉钉、企业微信markdown的加粗写法
+func doubleStar(s string) string {
+	return "**" + s + "**"
+}
@@ -0,0 +1,53 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+
+	"ocean-marketing/internal/config"
+)
+
+// weComColors 各级别标题的颜色（企业微信markdown只支持这三种）
+var weComColors = map[Severity]string{
+	SeverityInfo:     "info",
+	SeverityWarning:  "comment",
+	SeverityCritical: "warning",
+}
+
+// WeCom 企业微信群机器人，发送markdown消息
+type WeCom struct {
+	cfg config.WebhookConfig
+}
+
+// NewWeCom 创建企业微信通道
+func NewWeCom(cfg config.WebhookConfig) *WeCom {
+	return &WeCom{cfg: cfg}
+}
+
+// Name 通道名
+func (w *WeCom) Name() string {
+	return "wecom"
+}
+
+// Notify 发送markdown消息
+func (w *WeCom) Notify(ctx context.Context, a Alert) error {
+	title := fmt.Sprintf(`<font color="%s">%s</font>`, weComColors[a.Severity], header(a))
+	body := map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"content": "### " + title + "\n" + markdown(a, doubleStar),
+		},
+	}
+
+	var result struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err := postJSON(ctx, w.cfg.WebhookURL, body, &result); err != nil {
+		return err
+	}
+	if result.ErrCode != 0 {
+		return fmt.Errorf("alert: wecom errcode %d: %s", result.ErrCode, result.ErrMsg)
+	}
+	return nil
+}
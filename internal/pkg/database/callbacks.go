@@ -0,0 +1,30 @@
+package database
+
+import "gorm.io/gorm"
+
+// registerCallbacks 在create、query、update、delete、row、raw操作前后注册名为 {name}:before_{操作} 和 {name}:after_{操作} 的回调
+func registerCallbacks(db *gorm.DB, name string, before, after func(operation string) func(*gorm.DB)) error {
+	cb := db.Callback()
+	hooks := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+
+	for _, h := range hooks {
+		if err := h.before(name+":before_"+h.operation, before(h.operation)); err != nil {
+			return err
+		}
+		if err := h.after(name+":after_"+h.operation, after(h.operation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
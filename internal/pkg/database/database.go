@@ -1,17 +1,20 @@
 package database
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"ocean-marketing/internal/config"
 	"ocean-marketing/internal/pkg/logger"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"go.uber.org/zap"
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	gormLogger "gorm.io/gorm/logger"
 )
 
 var DB *gorm.DB
@@ -48,15 +51,11 @@ func Init(cfg config.DatabaseConfig) {
 			dsn += fmt.Sprintf("&writeTimeout=%ds", cfg.WriteTimeout)
 		}
 
-		DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
-			Logger: gormLogger.Default.LogMode(gormLogger.Info),
-		})
+		DB, err = gorm.Open(mysql.Open(dsn), gormConfig(cfg))
 	case "postgres":
 		dsn = fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=disable TimeZone=Asia/Shanghai",
 			cfg.Host, cfg.Username, cfg.Password, cfg.Database, cfg.Port)
-		DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-			Logger: gormLogger.Default.LogMode(gormLogger.Info),
-		})
+		DB, err = gorm.Open(postgres.Open(dsn), gormConfig(cfg))
 	default:
 		logger.Fatal("不支持的数据库驱动", zap.String("driver", cfg.Driver))
 	}
@@ -65,6 +64,16 @@ func Init(cfg config.DatabaseConfig) {
 		logger.Fatal("数据库连接失败", zap.Error(err))
 	}
 
+	// SQL链路追踪
+	if err := DB.Use(&tracingPlugin{system: cfg.Driver}); err != nil {
+		logger.Fatal("注册数据库链路追踪失败", zap.Error(err))
+	}
+
+	// SQL耗时指标
+	if err := DB.Use(&metricsPlugin{}); err != nil {
+		logger.Fatal("注册数据库指标失败", zap.Error(err))
+	}
+
 	// 获取底层的sql.DB
 	sqlDB, err := DB.DB()
 	if err != nil {
@@ -76,6 +85,9 @@ func Init(cfg config.DatabaseConfig) {
 	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
 	sqlDB.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Second)
 
+	// 连接池指标：go_sql_open_connections、go_sql_in_use_connections、go_sql_idle_connections、go_sql_wait_count_total 等
+	prometheus.MustRegister(collectors.NewDBStatsCollector(sqlDB, cfg.Database))
+
 	// 测试数据库连接
 	if err := sqlDB.Ping(); err != nil {
 		logger.Fatal("数据库连接测试失败", zap.Error(err))
@@ -84,11 +96,35 @@ func Init(cfg config.DatabaseConfig) {
 	logger.Info("数据库连接成功", zap.String("driver", cfg.Driver))
 }
 
+// gormConfig GORM配置，SQL日志写入zap并按 database.slow_threshold 记录慢查询
+func gormConfig(cfg config.DatabaseConfig) *gorm.Config {
+	return &gorm.Config{
+		Logger: &sqlLogger{slowThreshold: time.Duration(cfg.SlowThreshold) * time.Millisecond},
+	}
+}
+
 // GetDB 获取数据库实例
 func GetDB() *gorm.DB {
 	return DB
 }
 
+// WithContext 获取绑定上下文的数据库实例，SQL会作为上下文中span的子span记录
+func WithContext(ctx context.Context) *gorm.DB {
+	return DB.WithContext(ctx)
+}
+
+// Ping 检查数据库连接
+func Ping(ctx context.Context) error {
+	if DB == nil {
+		return errors.New("database: not initialized")
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
 // Close 关闭数据库连接
 func Close() error {
 	if DB != nil {
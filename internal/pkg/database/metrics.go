@@ -0,0 +1,76 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+// startKey 在gorm.DB实例中保存开始时间的键
+const startKey = "metrics:start"
+
+var (
+	// SQL执行耗时
+	queryDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "db_query_duration_seconds",
+			Help:    "Duration of database queries in seconds",
+			Buckets: []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+		},
+		[]string{"operation", "table"},
+	)
+
+	// SQL执行失败次数，不含记录不存在
+	queryErrorsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "db_query_errors_total",
+			Help: "Total number of failed database queries",
+		},
+		[]string{"operation", "table"},
+	)
+)
+
+// metricsPlugin 按操作和表统计SQL耗时与失败次数
+type metricsPlugin struct{}
+
+// Name 插件名称
+func (p *metricsPlugin) Name() string {
+	return "metrics"
+}
+
+// Initialize 在各类操作前后注册回调
+func (p *metricsPlugin) Initialize(db *gorm.DB) error {
+	return registerCallbacks(db, p.Name(), func(string) func(*gorm.DB) { return p.before }, p.after)
+}
+
+// before 记录开始时间
+func (p *metricsPlugin) before(db *gorm.DB) {
+	db.InstanceSet(startKey, time.Now())
+}
+
+// after 记录耗时，原生SQL没有表名时记为unknown
+func (p *metricsPlugin) after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(startKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+
+		queryDuration.WithLabelValues(operation, table).Observe(time.Since(start).Seconds())
+		if err := db.Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			queryErrorsTotal.WithLabelValues(operation, table).Inc()
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"errors"
+
+	"ocean-marketing/internal/pkg/tracer"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// spanKey 在gorm.DB实例中保存span的键
+const spanKey = "tracing:span"
+
+// tracingPlugin 为每条SQL创建子span，需要通过 WithContext 传入请求上下文
+type tracingPlugin struct {
+	system string
+}
+
+// Name 插件名称
+func (p *tracingPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize 在各类操作前后注册回调
+func (p *tracingPlugin) Initialize(db *gorm.DB) error {
+	return registerCallbacks(db, p.Name(), p.before, func(string) func(*gorm.DB) { return p.after })
+}
+
+// before 开始span，上下文中没有span时不记录，避免后台轮询产生大量单独的trace
+func (p *tracingPlugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil || !trace.SpanContextFromContext(ctx).IsValid() {
+			return
+		}
+
+		_, span := tracer.StartSpan(ctx, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", p.system),
+				semconv.DBOperation(operation),
+			),
+		)
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+// after 记录SQL、表名和影响行数后结束span
+func (p *tracingPlugin) after(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	// 只记录带占位符的SQL，不包含参数值
+	span.SetAttributes(
+		semconv.DBStatement(db.Statement.SQL.String()),
+		semconv.DBSQLTable(db.Statement.Table),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+
+	if err := db.Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
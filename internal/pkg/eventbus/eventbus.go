@@ -0,0 +1,241 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"ocean-marketing/internal/pkg/logger"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+// 异步订阅者队列已满而丢弃的事件数
+var eventsDroppedTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "eventbus_events_dropped_total",
+		Help: "Total number of events dropped because an async subscriber queue was full",
+	},
+	[]string{"event_type"},
+)
+
+// AllEvents 订阅所有事件类型
+const AllEvents = "*"
+
+// Event 领域事件
+type Event interface {
+	// EventType 事件类型，如 example.created
+	EventType() string
+	// AggregateType 聚合类型，如 example
+	AggregateType() string
+	// AggregateID 聚合ID
+	AggregateID() string
+}
+
+// Handler 事件处理函数
+type Handler func(ctx context.Context, event Event) error
+
+// asyncSubscriber 异步订阅者，使用独立goroutine按顺序处理；
+// queue不会被关闭，Close时通过stop通知处理完剩余事件后退出，避免与Publish并发发送时panic
+type asyncSubscriber struct {
+	eventType string
+	handler   Handler
+	queue     chan asyncEvent
+	stop      chan struct{}
+}
+
+type asyncEvent struct {
+	ctx   context.Context
+	event Event
+}
+
+// Bus 进程内事件总线
+type Bus struct {
+	mu        sync.RWMutex
+	sync      map[string][]Handler
+	async     map[string][]*asyncSubscriber
+	queueSize int
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// New 创建事件总线，queueSize为每个异步订阅者的缓冲大小
+func New(queueSize int) *Bus {
+	if queueSize <= 0 {
+		queueSize = 1024
+	}
+	return &Bus{
+		sync:      make(map[string][]Handler),
+		async:     make(map[string][]*asyncSubscriber),
+		queueSize: queueSize,
+	}
+}
+
+// Subscribe 注册同步订阅者，在Publish调用方的goroutine中执行
+func (b *Bus) Subscribe(eventType string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sync[eventType] = append(b.sync[eventType], handler)
+}
+
+// SubscribeAsync 注册异步订阅者，事件进入订阅者自己的队列后按顺序处理
+func (b *Bus) SubscribeAsync(eventType string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := &asyncSubscriber{
+		eventType: eventType,
+		handler:   handler,
+		queue:     make(chan asyncEvent, b.queueSize),
+		stop:      make(chan struct{}),
+	}
+	b.async[eventType] = append(b.async[eventType], sub)
+
+	b.wg.Add(1)
+	go b.consume(sub)
+}
+
+// Publish 发布事件，返回同步订阅者的错误；异步订阅者队列已满时丢弃事件，不阻塞发布方
+func (b *Bus) Publish(ctx context.Context, events ...Event) error {
+	var errs []error
+	for _, event := range events {
+		handlers, subs, err := b.subscribers(event.EventType())
+		if err != nil {
+			return err
+		}
+
+		for _, handler := range handlers {
+			if err := safeHandle(ctx, handler, event); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", event.EventType(), err))
+			}
+		}
+
+		for _, sub := range subs {
+			select {
+			// 异步处理不应受请求结束后ctx取消的影响
+			case sub.queue <- asyncEvent{ctx: context.WithoutCancel(ctx), event: event}:
+			default:
+				eventsDroppedTotal.WithLabelValues(event.EventType()).Inc()
+				logger.FromContext(ctx).Warn("异步订阅者队列已满，丢弃事件",
+					zap.String("event_type", event.EventType()),
+					zap.String("aggregate_id", event.AggregateID()))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// subscribers 在锁内复制事件类型对应的订阅者，订阅者在锁外执行
+func (b *Bus) subscribers(eventType string) ([]Handler, []*asyncSubscriber, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return nil, nil, errors.New("eventbus: bus closed")
+	}
+	return b.handlersFor(eventType), b.asyncFor(eventType), nil
+}
+
+// Close 停止接收事件并等待异步订阅者处理完队列
+func (b *Bus) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	for _, subs := range b.async {
+		for _, sub := range subs {
+			close(sub.stop)
+		}
+	}
+	b.mu.Unlock()
+
+	b.wg.Wait()
+}
+
+// handlersFor 获取事件类型对应的同步订阅者（含通配订阅）
+func (b *Bus) handlersFor(eventType string) []Handler {
+	handlers := append([]Handler{}, b.sync[eventType]...)
+	return append(handlers, b.sync[AllEvents]...)
+}
+
+// asyncFor 获取事件类型对应的异步订阅者（含通配订阅）
+func (b *Bus) asyncFor(eventType string) []*asyncSubscriber {
+	subs := append([]*asyncSubscriber{}, b.async[eventType]...)
+	return append(subs, b.async[AllEvents]...)
+}
+
+// consume 异步订阅者的处理循环，收到stop后处理完队列中剩余的事件再退出
+func (b *Bus) consume(sub *asyncSubscriber) {
+	defer b.wg.Done()
+
+	for {
+		select {
+		case item := <-sub.queue:
+			b.handleAsync(sub, item)
+		case <-sub.stop:
+			for {
+				select {
+				case item := <-sub.queue:
+					b.handleAsync(sub, item)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// handleAsync 执行异步订阅者，错误只记录日志
+func (b *Bus) handleAsync(sub *asyncSubscriber, item asyncEvent) {
+	if err := safeHandle(item.ctx, sub.handler, item.event); err != nil {
+		logger.FromContext(item.ctx).Error("异步事件处理失败",
+			zap.Error(err),
+			zap.String("event_type", item.event.EventType()),
+			zap.String("aggregate_id", item.event.AggregateID()))
+	}
+}
+
+// safeHandle 执行订阅者并把panic转换为错误
+func safeHandle(ctx context.Context, handler Handler, event Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("eventbus: handler panic: %v", r)
+		}
+	}()
+
+	return handler(ctx, event)
+}
+
+var defaultBus = New(0)
+
+// Default 获取默认事件总线
+func Default() *Bus {
+	return defaultBus
+}
+
+// Subscribe 在默认总线上注册同步订阅者
+func Subscribe(eventType string, handler Handler) {
+	defaultBus.Subscribe(eventType, handler)
+}
+
+// SubscribeAsync 在默认总线上注册异步订阅者
+func SubscribeAsync(eventType string, handler Handler) {
+	defaultBus.SubscribeAsync(eventType, handler)
+}
+
+// Publish 在默认总线上发布事件
+func Publish(ctx context.Context, events ...Event) error {
+	return defaultBus.Publish(ctx, events...)
+}
+
+// Close 关闭默认总线
+func Close() {
+	defaultBus.Close()
+}
@@ -0,0 +1,49 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+
+	"ocean-marketing/pkg/mq"
+)
+
+// ToMessage 将领域事件转换为MQ消息
+func ToMessage(event Event) (mq.Message, error) {
+	data, err := EventData(event)
+	if err != nil {
+		return mq.Message{}, err
+	}
+
+	return mq.Message{
+		ID:   mq.NewMessageID(),
+		Type: event.EventType(),
+		Data: data,
+	}, nil
+}
+
+// EventData 将事件结构体按json标签展开为map
+func EventData(event Event) (map[string]interface{}, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// NewMQForwarder 创建把事件转发到MQ的订阅者，路由键为事件类型
+func NewMQForwarder(client *mq.Client, exchange string) Handler {
+	return func(ctx context.Context, event Event) error {
+		message, err := ToMessage(event)
+		if err != nil {
+			return err
+		}
+
+		return client.Publish(ctx, exchange, event.EventType(), message)
+	}
+}
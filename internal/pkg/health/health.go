@@ -0,0 +1,197 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 检查状态
+const (
+	StatusHealthy = "healthy"
+	// StatusDegraded 只有非关键检查失败
+	StatusDegraded  = "degraded"
+	StatusUnhealthy = "unhealthy"
+)
+
+const defaultTimeout = 3 * time.Second
+
+// Checker 检查函数，返回nil表示健康
+type Checker func(ctx context.Context) error
+
+// Check 健康检查项
+type Check struct {
+	Name  string
+	Check Checker
+	// Timeout 单次检查超时，默认3秒
+	Timeout time.Duration
+	// Critical 关键依赖，失败时服务整体不健康且未就绪
+	Critical bool
+}
+
+// Result 单项检查结果
+type Result struct {
+	Status    string  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report 健康检查报告
+type Report struct {
+	Status    string            `json:"status"`
+	CheckedAt time.Time         `json:"checked_at"`
+	Checks    map[string]Result `json:"checks"`
+}
+
+// Registry 健康检查注册表，检查结果在cacheTTL内复用，避免探针频繁访问依赖
+type Registry struct {
+	mu       sync.RWMutex
+	checks   []Check
+	cacheTTL time.Duration
+
+	// runMu 保证同一时间只有一次检查在执行，并发请求等待同一结果
+	runMu  sync.Mutex
+	cached *Report
+}
+
+// NewRegistry 创建注册表
+func NewRegistry(cacheTTL time.Duration) *Registry {
+	return &Registry{cacheTTL: cacheTTL}
+}
+
+// SetCacheTTL 设置结果缓存时间
+func (r *Registry) SetCacheTTL(ttl time.Duration) {
+	r.runMu.Lock()
+	defer r.runMu.Unlock()
+
+	r.cacheTTL = ttl
+	r.cached = nil
+}
+
+// Register 注册检查项，同名检查项会被替换
+func (r *Registry) Register(check Check) {
+	if check.Timeout <= 0 {
+		check.Timeout = defaultTimeout
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, c := range r.checks {
+		if c.Name == check.Name {
+			r.checks[i] = check
+			return
+		}
+	}
+	r.checks = append(r.checks, check)
+}
+
+// Run 执行全部检查，缓存未过期时直接返回缓存结果
+func (r *Registry) Run(ctx context.Context) Report {
+	r.runMu.Lock()
+	defer r.runMu.Unlock()
+
+	if r.cached != nil && time.Since(r.cached.CheckedAt) < r.cacheTTL {
+		return *r.cached
+	}
+
+	// 结果会被其他请求复用，不受发起请求的取消影响
+	ctx = context.WithoutCancel(ctx)
+
+	r.mu.RLock()
+	checks := append([]Check{}, r.checks...)
+	r.mu.RUnlock()
+	sort.Slice(checks, func(i, j int) bool { return checks[i].Name < checks[j].Name })
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{
+		Status:    StatusHealthy,
+		CheckedAt: time.Now(),
+		Checks:    make(map[string]Result, len(checks)),
+	}
+	for i, check := range checks {
+		result := results[i]
+		report.Checks[check.Name] = result
+		if result.Status == StatusHealthy {
+			continue
+		}
+		if check.Critical {
+			report.Status = StatusUnhealthy
+		} else if report.Status == StatusHealthy {
+			report.Status = StatusDegraded
+		}
+	}
+
+	r.cached = &report
+	return report
+}
+
+// run 在超时限制内执行单项检查
+func run(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, check.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				done <- fmt.Errorf("health: check panic: %v", rec)
+			}
+		}()
+		done <- check.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("health: check timed out after %s", check.Timeout)
+	}
+
+	result := Result{
+		Status:    StatusHealthy,
+		Critical:  check.Critical,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusUnhealthy
+		result.Error = err.Error()
+	}
+	return result
+}
+
+var defaultRegistry = NewRegistry(2 * time.Second)
+
+// Default 获取默认注册表
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// Register 在默认注册表上注册检查项
+func Register(check Check) {
+	defaultRegistry.Register(check)
+}
+
+// Run 执行默认注册表上的检查
+func Run(ctx context.Context) Report {
+	return defaultRegistry.Run(ctx)
+}
+
+// SetCacheTTL 设置默认注册表的结果缓存时间
+func SetCacheTTL(ttl time.Duration) {
+	defaultRegistry.SetCacheTTL(ttl)
+}
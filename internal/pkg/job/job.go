@@ -0,0 +1,152 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"ocean-marketing/internal/model"
+	"ocean-marketing/internal/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUnknownJob 任务处理器未注册
+	ErrUnknownJob = errors.New("job: handler not registered")
+	// ErrDuplicate 相同唯一键的任务尚未结束
+	ErrDuplicate = errors.New("job: duplicate unique key")
+)
+
+// logModule 模块日志名，级别可通过 log.modules.job 单独调整
+const logModule = "job"
+
+// Handler 任务处理函数，payload为入队时的JSON
+type Handler func(ctx context.Context, payload []byte) error
+
+var (
+	mu       sync.RWMutex
+	handlers = make(map[string]Handler)
+)
+
+// Register 按名称注册类型化的任务处理器
+func Register[T any](name string, fn func(ctx context.Context, payload T) error) {
+	RegisterRaw(name, func(ctx context.Context, raw []byte) error {
+		var payload T
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return fmt.Errorf("job: decode payload: %w", err)
+		}
+		return fn(ctx, payload)
+	})
+}
+
+// RegisterRaw 按名称注册处理原始JSON的任务处理器
+func RegisterRaw(name string, handler Handler) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := handlers[name]; ok {
+		panic(fmt.Sprintf("job: handler %s already registered", name))
+	}
+	handlers[name] = handler
+}
+
+// lookup 获取任务处理器
+func lookup(name string) (Handler, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	handler, ok := handlers[name]
+	return handler, ok
+}
+
+// options 入队选项
+type options struct {
+	priority    int
+	uniqueKey   string
+	delay       time.Duration
+	maxAttempts int
+}
+
+// Option 入队选项
+type Option func(*options)
+
+// WithPriority 设置优先级，越大越先执行
+func WithPriority(priority int) Option {
+	return func(o *options) { o.priority = priority }
+}
+
+// WithUniqueKey 设置唯一键，同一唯一键同时只能有一个未结束的任务
+func WithUniqueKey(key string) Option {
+	return func(o *options) { o.uniqueKey = key }
+}
+
+// WithDelay 延迟执行
+func WithDelay(delay time.Duration) Option {
+	return func(o *options) { o.delay = delay }
+}
+
+// WithMaxAttempts 设置最大执行次数（含首次）
+func WithMaxAttempts(n int) Option {
+	return func(o *options) { o.maxAttempts = n }
+}
+
+// Enqueue 任务入队
+func Enqueue(ctx context.Context, name string, payload interface{}, opts ...Option) (*model.Job, error) {
+	return EnqueueTx(database.GetDB().WithContext(ctx), name, payload, opts...)
+}
+
+// EnqueueTx 在指定事务中入队，任务与业务数据一起提交
+func EnqueueTx(tx *gorm.DB, name string, payload interface{}, opts ...Option) (*model.Job, error) {
+	if _, ok := lookup(name); !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+
+	o := options{maxAttempts: 3}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &model.Job{
+		Name:        name,
+		Payload:     string(body),
+		Priority:    o.priority,
+		Status:      model.JobStatusPending,
+		MaxAttempts: o.maxAttempts,
+		RunAt:       time.Now().Add(o.delay),
+	}
+
+	if o.uniqueKey != "" {
+		var count int64
+		if err := tx.Model(&model.Job{}).Where("unique_key = ?", o.uniqueKey).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicate, o.uniqueKey)
+		}
+		job.UniqueKey = &o.uniqueKey
+	}
+
+	// 并发入队时由唯一索引兜底
+	if err := tx.Create(job).Error; err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// Cleanup 删除结束时间早于before的已成功或已取消任务，失败任务保留供排查
+func Cleanup(ctx context.Context, before time.Time) (int64, error) {
+	result := database.GetDB().WithContext(ctx).
+		Where("status IN ? AND finished_at < ?", []string{model.JobStatusSucceeded, model.JobStatusCancelled}, before).
+		Delete(&model.Job{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,330 @@
+package job
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"ocean-marketing/internal/config"
+	"ocean-marketing/internal/model"
+	"ocean-marketing/internal/pkg/alert"
+	"ocean-marketing/internal/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxBackoff 重试间隔上限
+const maxBackoff = time.Hour
+
+// Pool 任务执行池
+type Pool struct {
+	db       *gorm.DB
+	cfg      config.JobConfig
+	workerID string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewPool 创建任务执行池
+func NewPool(db *gorm.DB, cfg config.JobConfig) *Pool {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Pool{
+		db:       db,
+		cfg:      cfg,
+		workerID: newWorkerID(),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Start 启动worker和过期租约回收
+func (p *Pool) Start() {
+	for i := 0; i < p.cfg.Concurrency; i++ {
+		p.wg.Add(1)
+		go p.work()
+	}
+
+	p.wg.Add(1)
+	go p.reap()
+
+	logger.Named(logModule).Info("任务执行池启动",
+		zap.String("worker_id", p.workerID),
+		zap.Int("concurrency", p.cfg.Concurrency))
+}
+
+// Stop 停止领取新任务，执行中的任务收到ctx取消后放回队列
+func (p *Pool) Stop() {
+	p.cancel()
+	p.wg.Wait()
+	logger.Named(logModule).Info("任务执行池已停止")
+}
+
+// work worker主循环
+func (p *Pool) work() {
+	defer p.wg.Done()
+
+	pollInterval := time.Duration(p.cfg.PollInterval) * time.Second
+	for {
+		if p.ctx.Err() != nil {
+			return
+		}
+
+		job, err := p.claim()
+		if err != nil {
+			logger.Named(logModule).Error("领取任务失败", zap.Error(err))
+		}
+		if job == nil {
+			select {
+			case <-p.ctx.Done():
+				return
+			case <-time.After(pollInterval):
+			}
+			continue
+		}
+
+		p.execute(job)
+	}
+}
+
+// claim 领取一个到期的任务，SKIP LOCKED 保证多实例不会重复领取
+func (p *Pool) claim() (*model.Job, error) {
+	var claimed *model.Job
+
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		var jobs []model.Job
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND run_at <= ?", model.JobStatusPending, now).
+			Order("priority DESC, id").
+			Limit(1).
+			Find(&jobs).Error; err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			return nil
+		}
+
+		job := jobs[0]
+		lockedUntil := now.Add(p.lease())
+		job.Status = model.JobStatusRunning
+		job.Attempts++
+		job.LockedBy = p.workerID
+		job.LockedUntil = &lockedUntil
+		job.StartedAt = &now
+
+		if err := tx.Model(&job).Updates(map[string]interface{}{
+			"status":       job.Status,
+			"attempts":     job.Attempts,
+			"locked_by":    job.LockedBy,
+			"locked_until": job.LockedUntil,
+			"started_at":   job.StartedAt,
+		}).Error; err != nil {
+			return err
+		}
+
+		claimed = &job
+		return nil
+	})
+
+	return claimed, err
+}
+
+// execute 执行任务并记录结果
+func (p *Pool) execute(job *model.Job) {
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(p.ctx, time.Duration(p.cfg.Timeout)*time.Second)
+	defer cancel()
+
+	// 续约租约，任务被取消时中止执行
+	heartbeatDone := make(chan struct{})
+	go p.heartbeat(ctx, job, cancel, heartbeatDone)
+
+	err := p.run(ctx, job)
+	cancel()
+	<-heartbeatDone
+
+	fields := []zap.Field{
+		zap.Uint("job_id", job.ID),
+		zap.String("name", job.Name),
+		zap.Int("attempt", job.Attempts),
+		zap.Duration("duration", time.Since(start)),
+	}
+
+	switch {
+	case err == nil:
+		p.finish(job, model.JobStatusSucceeded, "")
+		logger.Named(logModule).Info("任务执行成功", fields...)
+	case p.ctx.Err() != nil:
+		// 服务关闭导致中断，不计入执行次数
+		p.release(job)
+		logger.Named(logModule).Warn("任务因服务关闭被放回队列", fields...)
+	case job.Attempts >= job.MaxAttempts || errors.Is(err, ErrUnknownJob):
+		p.finish(job, model.JobStatusFailed, err.Error())
+		logger.Named(logModule).Error("任务执行失败", append(fields, zap.Error(err))...)
+		alert.Send(p.ctx, alert.Alert{
+			Severity: alert.SeverityWarning,
+			Title:    "后台任务失败: " + job.Name,
+			Content:  err.Error(),
+			Fields: []alert.Field{
+				alert.F("任务ID", job.ID),
+				alert.F("执行次数", job.Attempts),
+			},
+		})
+	default:
+		p.retry(job, err)
+		logger.Named(logModule).Warn("任务执行失败，等待重试", append(fields, zap.Error(err))...)
+	}
+}
+
+// run 调用处理器并把panic转换为错误
+func (p *Pool) run(ctx context.Context, job *model.Job) (err error) {
+	handler, ok := lookup(job.Name)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownJob, job.Name)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job: handler panic: %v", r)
+		}
+	}()
+
+	return handler(ctx, []byte(job.Payload))
+}
+
+// heartbeat 定期续约，任务不再由本worker持有（被取消）时取消ctx
+func (p *Pool) heartbeat(ctx context.Context, job *model.Job, cancel context.CancelFunc, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(p.lease() / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result := p.db.Model(&model.Job{}).
+				Where("id = ? AND status = ? AND locked_by = ?", job.ID, model.JobStatusRunning, p.workerID).
+				Update("locked_until", time.Now().Add(p.lease()))
+			if result.Error != nil {
+				logger.Named(logModule).Error("任务续约失败", zap.Error(result.Error), zap.Uint("job_id", job.ID))
+				continue
+			}
+			if result.RowsAffected == 0 {
+				logger.Named(logModule).Warn("任务已被取消，中止执行", zap.Uint("job_id", job.ID))
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// finish 任务结束，释放唯一键
+func (p *Pool) finish(job *model.Job, status, lastError string) {
+	p.update(job, map[string]interface{}{
+		"status":       status,
+		"last_error":   lastError,
+		"unique_key":   nil,
+		"locked_by":    "",
+		"locked_until": nil,
+		"finished_at":  time.Now(),
+	})
+}
+
+// retry 按指数退避安排下次执行
+func (p *Pool) retry(job *model.Job, err error) {
+	p.update(job, map[string]interface{}{
+		"status":       model.JobStatusPending,
+		"last_error":   err.Error(),
+		"locked_by":    "",
+		"locked_until": nil,
+		"run_at":       time.Now().Add(p.backoff(job.Attempts)),
+	})
+}
+
+// release 放回队列，本次执行不计数
+func (p *Pool) release(job *model.Job) {
+	p.update(job, map[string]interface{}{
+		"status":       model.JobStatusPending,
+		"attempts":     job.Attempts - 1,
+		"locked_by":    "",
+		"locked_until": nil,
+		"run_at":       time.Now(),
+	})
+}
+
+// update 只更新仍由本worker持有的任务，避免覆盖管理员的取消操作
+func (p *Pool) update(job *model.Job, values map[string]interface{}) {
+	err := p.db.Model(&model.Job{}).
+		Where("id = ? AND status = ? AND locked_by = ?", job.ID, model.JobStatusRunning, p.workerID).
+		Updates(values).Error
+	if err != nil {
+		logger.Named(logModule).Error("更新任务状态失败", zap.Error(err), zap.Uint("job_id", job.ID))
+	}
+}
+
+// reap 回收租约过期的任务（worker崩溃或失联）
+func (p *Pool) reap() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.lease())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+			result := p.db.Model(&model.Job{}).
+				Where("status = ? AND locked_until < ?", model.JobStatusRunning, time.Now()).
+				Updates(map[string]interface{}{
+					"status":       model.JobStatusPending,
+					"locked_by":    "",
+					"locked_until": nil,
+				})
+			if result.Error != nil {
+				logger.Named(logModule).Error("回收过期任务失败", zap.Error(result.Error))
+			} else if result.RowsAffected > 0 {
+				logger.Named(logModule).Warn("回收租约过期的任务", zap.Int64("count", result.RowsAffected))
+			}
+		}
+	}
+}
+
+// lease 执行租约时长
+func (p *Pool) lease() time.Duration {
+	return time.Duration(p.cfg.Lease) * time.Second
+}
+
+// backoff 第n次失败后的重试间隔
+func (p *Pool) backoff(attempts int) time.Duration {
+	delay := time.Duration(p.cfg.RetryBackoff) * time.Second
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay
+}
+
+// newWorkerID 生成worker标识
+func newWorkerID() string {
+	hostname, _ := os.Hostname()
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hostname + "-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,199 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ocean-marketing/internal/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// ErrHookTimeout 钩子在超时时间内未完成
+var ErrHookTimeout = errors.New("lifecycle: hook timed out")
+
+// Hook 生命周期钩子，按注册顺序启动、逆序停止
+type Hook struct {
+	Name string
+	// OnStart 启动时调用，可为空
+	OnStart func(ctx context.Context) error
+	// OnStop 停止时调用，可为空
+	OnStop func(ctx context.Context) error
+	// Timeout 单次调用超时，为0时只受整体超时限制；
+	// 停止时大于0的超时是独立的预算，前面的钩子耗尽整体超时后仍有时间刷新缓冲、关闭连接
+	Timeout time.Duration
+}
+
+// Manager 管理各子系统的启动和停止顺序
+type Manager struct {
+	mu      sync.Mutex
+	hooks   []Hook
+	started int
+	ready   atomic.Bool
+}
+
+// New 创建生命周期管理器
+func New() *Manager {
+	return &Manager{}
+}
+
+// Append 注册钩子，需在Start之前调用
+func (m *Manager) Append(hook Hook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.hooks = append(m.hooks, hook)
+}
+
+// Start 按注册顺序启动，任一钩子失败时逆序停止已启动的钩子并返回错误
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	hooks := append([]Hook{}, m.hooks...)
+	m.mu.Unlock()
+
+	for i, hook := range hooks {
+		if hook.OnStart != nil {
+			start := time.Now()
+			if err := call(ctx, hook.Name, hook.Timeout, hook.OnStart); err != nil {
+				logger.Error("启动失败", zap.String("hook", hook.Name), zap.Error(err))
+
+				// 超时的钩子可能已部分启动（仍在后台执行），回滚时一并调用其OnStop
+				if errors.Is(err, ErrHookTimeout) {
+					m.setStarted(i + 1)
+				} else {
+					m.setStarted(i)
+				}
+				if stopErr := m.Stop(context.Background()); stopErr != nil {
+					logger.Error("回滚已启动的组件失败", zap.Error(stopErr))
+				}
+				return fmt.Errorf("lifecycle: start %s: %w", hook.Name, err)
+			}
+			logger.Info("启动完成", zap.String("hook", hook.Name), zap.Duration("duration", time.Since(start)))
+		}
+		m.setStarted(i + 1)
+	}
+
+	return nil
+}
+
+// Stop 标记为未就绪后逆序停止已启动的钩子，设置了超时的钩子只受自身超时限制，其余钩子受ctx限制，
+// 单个钩子失败或超时不影响后续钩子，返回所有错误
+func (m *Manager) Stop(ctx context.Context) error {
+	m.ready.Store(false)
+
+	m.mu.Lock()
+	hooks := append([]Hook{}, m.hooks[:m.started]...)
+	m.started = 0
+	m.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hook := hooks[i]
+		if hook.OnStop == nil {
+			continue
+		}
+
+		stopCtx := ctx
+		if hook.Timeout > 0 {
+			stopCtx = context.WithoutCancel(ctx)
+		}
+
+		start := time.Now()
+		if err := call(stopCtx, hook.Name, hook.Timeout, hook.OnStop); err != nil {
+			logger.Error("停止失败", zap.String("hook", hook.Name), zap.Error(err), zap.Duration("duration", time.Since(start)))
+			errs = append(errs, fmt.Errorf("lifecycle: stop %s: %w", hook.Name, err))
+			continue
+		}
+		logger.Info("已停止", zap.String("hook", hook.Name), zap.Duration("duration", time.Since(start)))
+	}
+
+	return errors.Join(errs...)
+}
+
+// SetReady 设置是否就绪（可以接收流量）
+func (m *Manager) SetReady(ready bool) {
+	m.ready.Store(ready)
+}
+
+// Ready 是否就绪
+func (m *Manager) Ready() bool {
+	return m.ready.Load()
+}
+
+func (m *Manager) setStarted(n int) {
+	m.mu.Lock()
+	m.started = n
+	m.mu.Unlock()
+}
+
+// call 在超时限制内执行钩子，超时后不再等待；钩子仍在后台执行，结束时记录日志
+func call(ctx context.Context, name string, timeout time.Duration, fn func(ctx context.Context) error) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("lifecycle: hook panic: %v", r)
+			}
+		}()
+		done <- fn(ctx)
+	}()
+
+	start := time.Now()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		logger.Warn("钩子超时，仍在后台执行", zap.String("hook", name))
+		go func() {
+			err := <-done
+			logger.Warn("超时的钩子已结束", zap.String("hook", name), zap.Error(err), zap.Duration("duration", time.Since(start)))
+		}()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return ErrHookTimeout
+		}
+		return ctx.Err()
+	}
+}
+
+var defaultManager = New()
+
+// Default 获取默认生命周期管理器
+func Default() *Manager {
+	return defaultManager
+}
+
+// Append 在默认管理器上注册钩子
+func Append(hook Hook) {
+	defaultManager.Append(hook)
+}
+
+// Start 启动默认管理器
+func Start(ctx context.Context) error {
+	return defaultManager.Start(ctx)
+}
+
+// Stop 停止默认管理器
+func Stop(ctx context.Context) error {
+	return defaultManager.Stop(ctx)
+}
+
+// SetReady 设置默认管理器是否就绪
+func SetReady(ready bool) {
+	defaultManager.SetReady(ready)
+}
+
+// Ready 默认管理器是否就绪
+func Ready() bool {
+	return defaultManager.Ready()
+}
@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	userIDKey
+)
+
+// WithRequestID 把请求ID保存到上下文
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestIDFromContext 获取上下文中的请求ID
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// WithUserID 把当前用户ID保存到上下文
+func WithUserID(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// UserIDFromContext 获取上下文中的用户ID，未登录时返回0
+func UserIDFromContext(ctx context.Context) uint {
+	userID, _ := ctx.Value(userIDKey).(uint)
+	return userID
+}
+
+// FromContext 获取附带请求ID、trace ID、span ID和用户ID的logger，便于日志与链路关联
+func FromContext(ctx context.Context) *zap.Logger {
+	if logger == nil {
+		return zap.NewNop()
+	}
+
+	fields := ContextFields(ctx)
+	if len(fields) == 0 {
+		return logger
+	}
+	return logger.With(fields...)
+}
+
+// ContextFields 上下文中的请求ID、trace ID、span ID和用户ID字段，用于模块logger：
+// logger.Named("mq").With(logger.ContextFields(ctx)...)
+func ContextFields(ctx context.Context) []zap.Field {
+	if ctx == nil {
+		return nil
+	}
+
+	fields := make([]zap.Field, 0, 4)
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		fields = append(fields, zap.String("request_id", requestID))
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		fields = append(fields,
+			zap.String("trace_id", spanContext.TraceID().String()),
+			zap.String("span_id", spanContext.SpanID().String()),
+		)
+	}
+	if userID := UserIDFromContext(ctx); userID != 0 {
+		fields = append(fields, zap.Uint("user_id", userID))
+	}
+	return fields
+}
@@ -0,0 +1,283 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// levels 支持的日志级别
+var levels = map[string]zapcore.Level{
+	"debug": zapcore.DebugLevel,
+	"info":  zapcore.InfoLevel,
+	"warn":  zapcore.WarnLevel,
+	"error": zapcore.ErrorLevel,
+	"fatal": zapcore.FatalLevel,
+}
+
+// parseLevel 解析日志级别，未知级别按info处理
+func parseLevel(s string) zapcore.Level {
+	if l, ok := levels[s]; ok {
+		return l
+	}
+	return zapcore.InfoLevel
+}
+
+// ValidLevel 是否为支持的日志级别
+func ValidLevel(s string) bool {
+	_, ok := levels[s]
+	return ok
+}
+
+// SetLevel 运行时调整日志级别
+func SetLevel(s string) {
+	level.SetLevel(parseLevel(s))
+}
+
+// GetLevel 获取当前日志级别
+func GetLevel() string {
+	return level.Level().String()
+}
+
+var (
+	// moduleLevels 按模块覆盖的日志级别，写时复制，记录日志时无需加锁
+	moduleLevels atomic.Pointer[map[string]zapcore.Level]
+	moduleMu     sync.Mutex
+)
+
+// setModuleLevels 用配置替换全部模块级别
+func setModuleLevels(modules map[string]string) {
+	moduleMu.Lock()
+	defer moduleMu.Unlock()
+
+	next := make(map[string]zapcore.Level, len(modules))
+	for module, l := range modules {
+		next[module] = parseLevel(l)
+	}
+	moduleLevels.Store(&next)
+}
+
+// SetModuleLevel 设置模块日志级别，level为空时恢复为跟随全局级别
+func SetModuleLevel(module, l string) {
+	moduleMu.Lock()
+	defer moduleMu.Unlock()
+
+	current := ModuleLevels()
+	next := make(map[string]zapcore.Level, len(current)+1)
+	for name, value := range current {
+		next[name] = parseLevel(value)
+	}
+	if l == "" {
+		delete(next, module)
+	} else {
+		next[module] = parseLevel(l)
+	}
+	moduleLevels.Store(&next)
+}
+
+// ModuleLevels 获取各模块覆盖的日志级别
+func ModuleLevels() map[string]string {
+	result := make(map[string]string)
+	if current := moduleLevels.Load(); current != nil {
+		for module, l := range *current {
+			result[module] = l.String()
+		}
+	}
+	return result
+}
+
+// moduleEnabler 模块有覆盖级别时使用覆盖级别，否则跟随全局级别
+type moduleEnabler string
+
+func (m moduleEnabler) Enabled(l zapcore.Level) bool {
+	if current := moduleLevels.Load(); current != nil {
+		if override, ok := (*current)[string(m)]; ok {
+			return override.Enabled(l)
+		}
+	}
+	return level.Enabled(l)
+}
+
+// levelCore 按enabler过滤后写入共用的输出core
+type levelCore struct {
+	zapcore.Core
+	enabler zapcore.LevelEnabler
+}
+
+func (c *levelCore) Enabled(l zapcore.Level) bool {
+	return c.enabler.Enabled(l)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), enabler: c.enabler}
+}
+
+func (c *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(entry.Level) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
+
+// named 已创建的模块logger
+var named sync.Map
+
+// resetNamed 重新初始化后清空模块logger缓存
+func resetNamed() {
+	named.Range(func(key, _ interface{}) bool {
+		named.Delete(key)
+		return true
+	})
+}
+
+// Named 获取模块logger，级别可通过 log.modules 或管理接口单独调整，需在Init之后调用
+func Named(module string) *zap.Logger {
+	if output == nil {
+		return zap.NewNop()
+	}
+	if l, ok := named.Load(module); ok {
+		return l.(*zap.Logger)
+	}
+
+	l := zap.New(&levelCore{Core: output, enabler: moduleEnabler(module)}, options...).Named(module)
+	actual, _ := named.LoadOrStore(module, l)
+	return actual.(*zap.Logger)
+}
+
+// LevelState 日志级别及计划自动恢复的时间
+type LevelState struct {
+	Level    string     `json:"level"`
+	RevertAt *time.Time `json:"revert_at,omitempty"`
+}
+
+// LevelStatus 全局和各模块的日志级别
+type LevelStatus struct {
+	LevelState
+	Modules map[string]LevelState `json:"modules"`
+}
+
+// pendingRevert 计划中的自动恢复
+type pendingRevert struct {
+	previous string
+	at       time.Time
+	timer    *time.Timer
+}
+
+var (
+	revertMu sync.Mutex
+	// reverts 键为模块名，全局级别为空字符串
+	reverts = make(map[string]*pendingRevert)
+)
+
+// ChangeLevel 调整全局（module为空）或模块的日志级别，revertAfter大于0时到期自动恢复为调整前的级别；
+// 模块level为空表示恢复为跟随全局级别
+func ChangeLevel(module, l string, revertAfter time.Duration) error {
+	if module == "" && l == "" {
+		return fmt.Errorf("logger: level is required")
+	}
+	if l != "" && !ValidLevel(l) {
+		return fmt.Errorf("logger: unknown level %q", l)
+	}
+
+	revertMu.Lock()
+	defer revertMu.Unlock()
+
+	previous := currentLevel(module)
+	if pending, ok := reverts[module]; ok {
+		// 连续调整时恢复到最初的级别
+		pending.timer.Stop()
+		previous = pending.previous
+		delete(reverts, module)
+	}
+
+	applyLevel(module, l)
+
+	if revertAfter > 0 {
+		pending := &pendingRevert{
+			previous: previous,
+			at:       time.Now().Add(revertAfter),
+		}
+		pending.timer = time.AfterFunc(revertAfter, func() { revert(module, pending) })
+		reverts[module] = pending
+	}
+
+	Info("日志级别已调整",
+		zap.String("module", module),
+		zap.String("from", previous),
+		zap.String("to", l),
+		zap.Duration("revert_after", revertAfter))
+	return nil
+}
+
+// revert 到期恢复级别，计划已被新的调整替换时不做处理
+func revert(module string, pending *pendingRevert) {
+	revertMu.Lock()
+	defer revertMu.Unlock()
+
+	if reverts[module] != pending {
+		return
+	}
+	delete(reverts, module)
+
+	applyLevel(module, pending.previous)
+	Info("日志级别已自动恢复", zap.String("module", module), zap.String("to", pending.previous))
+}
+
+// cancelReverts 取消匹配模块的自动恢复，配置重载后以配置为准
+func cancelReverts(match func(module string) bool) {
+	revertMu.Lock()
+	defer revertMu.Unlock()
+
+	for module, pending := range reverts {
+		if match(module) {
+			pending.timer.Stop()
+			delete(reverts, module)
+		}
+	}
+}
+
+// currentLevel 当前级别，模块未覆盖时返回空字符串
+func currentLevel(module string) string {
+	if module == "" {
+		return GetLevel()
+	}
+	return ModuleLevels()[module]
+}
+
+// applyLevel 设置全局或模块级别
+func applyLevel(module, l string) {
+	if module == "" {
+		SetLevel(l)
+		return
+	}
+	SetModuleLevel(module, l)
+}
+
+// Levels 获取全局和各模块的日志级别
+func Levels() LevelStatus {
+	revertMu.Lock()
+	defer revertMu.Unlock()
+
+	state := func(module, l string) LevelState {
+		s := LevelState{Level: l}
+		if pending, ok := reverts[module]; ok {
+			at := pending.at
+			s.RevertAt = &at
+		}
+		return s
+	}
+
+	status := LevelStatus{
+		LevelState: state("", GetLevel()),
+		Modules:    make(map[string]LevelState),
+	}
+
+	for module, l := range ModuleLevels() {
+		status.Modules[module] = state(module, l)
+	}
+	return status
+}
@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// useNopLogger 测试期间使用不输出的根logger，结束后恢复全局级别、模块级别和自动恢复计划
+func useNopLogger(t *testing.T) {
+	t.Helper()
+
+	previous := logger
+	logger = zap.NewNop()
+	SetLevel("info")
+	setModuleLevels(nil)
+
+	t.Cleanup(func() {
+		cancelReverts(func(string) bool { return true })
+		SetLevel("info")
+		setModuleLevels(nil)
+		logger = previous
+	})
+}
+
+// eventually 在超时前轮询直到条件满足
+func eventually(t *testing.T, cond func() bool) bool {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return false
+}
+
+func TestChangeLevel(t *testing.T) {
+	type change struct {
+		module      string
+		level       string
+		revertAfter time.Duration
+	}
+
+	tests := []struct {
+		name    string
+		changes []change
+		module  string
+		// want 调整后的级别，模块为空字符串表示跟随全局
+		want string
+		// wantReverted 自动恢复后的级别，与want相同表示不恢复
+		wantReverted string
+	}{
+		{
+			name:         "全局级别到期恢复",
+			changes:      []change{{level: "debug", revertAfter: 50 * time.Millisecond}},
+			want:         "debug",
+			wantReverted: "info",
+		},
+		{
+			name:         "模块级别到期恢复为跟随全局",
+			changes:      []change{{module: "mq", level: "debug", revertAfter: 50 * time.Millisecond}},
+			module:       "mq",
+			want:         "debug",
+			wantReverted: "",
+		},
+		{
+			name: "连续调整恢复到最初的级别",
+			changes: []change{
+				{level: "debug", revertAfter: time.Hour},
+				{level: "warn", revertAfter: 50 * time.Millisecond},
+			},
+			want:         "warn",
+			wantReverted: "info",
+		},
+		{
+			name: "不带恢复时间的调整取消之前的恢复",
+			changes: []change{
+				{module: "mq", level: "debug", revertAfter: 50 * time.Millisecond},
+				{module: "mq", level: "error"},
+			},
+			module:       "mq",
+			want:         "error",
+			wantReverted: "error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useNopLogger(t)
+
+			for _, c := range tt.changes {
+				if err := ChangeLevel(c.module, c.level, c.revertAfter); err != nil {
+					t.Fatalf("ChangeLevel(%q, %q): %v", c.module, c.level, err)
+				}
+			}
+
+			if got := currentLevel(tt.module); got != tt.want {
+				t.Fatalf("level = %q, want %q", got, tt.want)
+			}
+
+			last := tt.changes[len(tt.changes)-1]
+			state := Levels().LevelState
+			if tt.module != "" {
+				state = Levels().Modules[tt.module]
+			}
+			if (state.RevertAt != nil) != (last.revertAfter > 0) {
+				t.Errorf("revert_at = %v, want scheduled %v", state.RevertAt, last.revertAfter > 0)
+			}
+
+			if tt.wantReverted == tt.want {
+				time.Sleep(100 * time.Millisecond)
+				if got := currentLevel(tt.module); got != tt.want {
+					t.Errorf("level after wait = %q, want %q", got, tt.want)
+				}
+				return
+			}
+			if !eventually(t, func() bool { return currentLevel(tt.module) == tt.wantReverted }) {
+				t.Errorf("level = %q, want reverted to %q", currentLevel(tt.module), tt.wantReverted)
+			}
+			if _, ok := Levels().Modules[tt.module]; tt.module != "" && tt.wantReverted == "" && ok {
+				t.Errorf("module %s should follow global level after revert", tt.module)
+			}
+		})
+	}
+}
+
+func TestChangeLevelInvalid(t *testing.T) {
+	useNopLogger(t)
+
+	tests := []struct {
+		name   string
+		module string
+		level  string
+	}{
+		{name: "全局级别为空", module: "", level: ""},
+		{name: "未知级别", module: "", level: "verbose"},
+		{name: "模块未知级别", module: "mq", level: "trace"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ChangeLevel(tt.module, tt.level, time.Minute); err == nil {
+				t.Errorf("ChangeLevel(%q, %q) error = nil, want error", tt.module, tt.level)
+			}
+			if got := GetLevel(); got != "info" {
+				t.Errorf("level = %q, want unchanged info", got)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package logger
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 
 	"ocean-marketing/internal/config"
 
@@ -11,7 +12,17 @@ import (
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-var logger *zap.Logger
+var (
+	logger *zap.Logger
+	// level 全局日志级别，支持运行时调整
+	level = zap.NewAtomicLevel()
+	// local 写控制台和本地文件的core
+	local zapcore.Core
+	// output 不做级别过滤的输出core（本地，开启SLS时同时投递），根logger和模块logger共用，由各自的levelCore过滤
+	output zapcore.Core
+	// options 根logger和模块logger共用的选项
+	options = []zap.Option{zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel)}
+)
 
 // Init 初始化日志
 func Init(cfg config.LogConfig) {
@@ -22,19 +33,7 @@ func Init(cfg config.LogConfig) {
 	}
 
 	// 设置日志级别
-	level := zapcore.InfoLevel
-	switch cfg.Level {
-	case "debug":
-		level = zapcore.DebugLevel
-	case "info":
-		level = zapcore.InfoLevel
-	case "warn":
-		level = zapcore.WarnLevel
-	case "error":
-		level = zapcore.ErrorLevel
-	case "fatal":
-		level = zapcore.FatalLevel
-	}
+	level.SetLevel(parseLevel(cfg.Level))
 
 	// 设置编码器
 	encoderConfig := zap.NewProductionEncoderConfig()
@@ -67,8 +66,33 @@ func Init(cfg config.LogConfig) {
 		consoleWriter,
 	)
 
-	core := zapcore.NewCore(encoder, multiWriter, level)
-	logger = zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
+	local = zapcore.NewCore(encoder, multiWriter, zapcore.DebugLevel)
+	build()
+	setModuleLevels(cfg.Modules)
+
+	// 配置热更新时同步日志级别
+	config.OnChange(func(old, new *config.Config) {
+		if old.Log.Level != new.Log.Level {
+			cancelReverts(func(module string) bool { return module == "" })
+			SetLevel(new.Log.Level)
+			Info("日志级别已更新", zap.String("from", old.Log.Level), zap.String("to", new.Log.Level))
+		}
+		if !reflect.DeepEqual(old.Log.Modules, new.Log.Modules) {
+			cancelReverts(func(module string) bool { return module != "" })
+			setModuleLevels(new.Log.Modules)
+			Info("模块日志级别已更新", zap.Any("modules", new.Log.Modules))
+		}
+	})
+}
+
+// build 组合输出并重建根logger，已创建的模块logger随之失效
+func build() {
+	output = local
+	if sls != nil {
+		output = zapcore.NewTee(local, sls)
+	}
+	logger = zap.New(&levelCore{Core: output, enabler: level}, options...)
+	resetNamed()
 }
 
 // Debug 调试日志
@@ -101,6 +125,13 @@ func Panic(msg string, fields ...zap.Field) {
 	logger.Panic(msg, fields...)
 }
 
+// Sync 刷新缓冲的日志
+func Sync() {
+	if logger != nil {
+		_ = logger.Sync()
+	}
+}
+
 // GetLogger 获取logger实例
 func GetLogger() *zap.Logger {
 	return logger
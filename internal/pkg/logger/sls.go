@@ -0,0 +1,488 @@
+package logger
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ocean-marketing/internal/config"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// SLS PutLogs 接口参数
+const (
+	slsAPIVersion      = "0.6.0"
+	slsSignatureMethod = "hmac-sha1"
+	slsContentType     = "application/x-protobuf"
+)
+
+// 日志丢弃原因
+const (
+	slsDropQueueFull  = "queue_full"
+	slsDropSendFailed = "send_failed"
+	slsDropClosed     = "closed"
+)
+
+var (
+	// 投递到SLS的日志条数
+	slsLogsSentTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sls_logs_sent_total",
+			Help: "Total number of log entries shipped to Aliyun SLS",
+		},
+	)
+
+	// 未能投递到SLS而丢弃的日志条数
+	slsLogsDroppedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sls_logs_dropped_total",
+			Help: "Total number of log entries dropped before reaching Aliyun SLS",
+		},
+		[]string{"reason"},
+	)
+)
+
+// slsLog 待投递的一条日志
+type slsLog struct {
+	time     time.Time
+	contents [][2]string
+}
+
+// slsCore 把日志交给后台批量投递到SLS的zap core，队列满时丢弃，不阻塞业务
+type slsCore struct {
+	zapcore.LevelEnabler
+	fields  []zapcore.Field
+	shipper *slsShipper
+}
+
+func (c *slsCore) With(fields []zapcore.Field) zapcore.Core {
+	return &slsCore{
+		LevelEnabler: c.LevelEnabler,
+		fields:       append(c.fields[:len(c.fields):len(c.fields)], fields...),
+		shipper:      c.shipper,
+	}
+}
+
+func (c *slsCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *slsCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range c.fields {
+		field.AddTo(enc)
+	}
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+
+	contents := make([][2]string, 0, len(enc.Fields)+4)
+	contents = append(contents, [2]string{"level", entry.Level.String()}, [2]string{"message", entry.Message})
+	if entry.LoggerName != "" {
+		contents = append(contents, [2]string{"logger", entry.LoggerName})
+	}
+	if entry.Caller.Defined {
+		contents = append(contents, [2]string{"caller", entry.Caller.TrimmedPath()})
+	}
+	for key, value := range enc.Fields {
+		contents = append(contents, [2]string{key, slsValue(value)})
+	}
+
+	c.shipper.enqueue(slsLog{time: entry.Time, contents: contents})
+
+	// 与zap自带core一致，Fatal/Panic前先刷新
+	if entry.Level > zapcore.ErrorLevel {
+		return c.Sync()
+	}
+	return nil
+}
+
+func (c *slsCore) Sync() error {
+	c.shipper.flush()
+	return nil
+}
+
+// slsValue 字段值转为字符串，复杂类型编码为JSON
+func slsValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return v.String()
+	}
+	if b, err := json.Marshal(value); err == nil {
+		return string(b)
+	}
+	return fmt.Sprint(value)
+}
+
+// slsShipper 后台攒批投递，投递慢或失败时队列积压，积压满后新日志直接丢弃
+type slsShipper struct {
+	client        *slsClient
+	batchSize     int
+	flushInterval time.Duration
+	maxRetries    int
+	queue         chan slsLog
+	flushes       chan chan struct{}
+	closing       chan struct{}
+	closeOnce     sync.Once
+	done          chan struct{}
+	// report 只写本地的logger，记录投递失败，避免失败日志再次进入队列
+	report *zap.Logger
+}
+
+// newSLSShipper 创建并启动投递器
+func newSLSShipper(cfg config.SLSConfig, client *slsClient, report *zap.Logger) *slsShipper {
+	s := &slsShipper{
+		client:        client,
+		batchSize:     cfg.BatchSize,
+		flushInterval: time.Duration(cfg.FlushInterval) * time.Millisecond,
+		maxRetries:    cfg.MaxRetries,
+		queue:         make(chan slsLog, cfg.QueueSize),
+		flushes:       make(chan chan struct{}),
+		closing:       make(chan struct{}),
+		done:          make(chan struct{}),
+		report:        report,
+	}
+	go s.run()
+	return s
+}
+
+// enqueue 加入待投递队列，队列满或已关闭时丢弃
+func (s *slsShipper) enqueue(log slsLog) {
+	select {
+	case <-s.closing:
+		slsLogsDroppedTotal.WithLabelValues(slsDropClosed).Inc()
+		return
+	default:
+	}
+
+	select {
+	case s.queue <- log:
+	default:
+		slsLogsDroppedTotal.WithLabelValues(slsDropQueueFull).Inc()
+	}
+}
+
+// flush 投递队列中已有的日志，返回时已投递完成（或已放弃）
+func (s *slsShipper) flush() {
+	ack := make(chan struct{})
+	select {
+	case s.flushes <- ack:
+	case <-s.done:
+		return
+	}
+	select {
+	case <-ack:
+	case <-s.done:
+	}
+}
+
+// close 投递剩余日志后停止，ctx到期时不再等待
+func (s *slsShipper) close(ctx context.Context) error {
+	s.closeOnce.Do(func() { close(s.closing) })
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *slsShipper) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]slsLog, 0, s.batchSize)
+	send := func() {
+		if len(batch) > 0 {
+			s.send(batch)
+			batch = batch[:0]
+		}
+	}
+	// drain 取出队列中已有的全部日志
+	drain := func() {
+		for {
+			select {
+			case log := <-s.queue:
+				batch = append(batch, log)
+				if len(batch) >= s.batchSize {
+					send()
+				}
+			default:
+				send()
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case log := <-s.queue:
+			batch = append(batch, log)
+			if len(batch) >= s.batchSize {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		case ack := <-s.flushes:
+			drain()
+			close(ack)
+		case <-s.closing:
+			drain()
+			return
+		}
+	}
+}
+
+// send 投递一批日志，限流、5xx和网络错误按指数退避重试，最终失败时计入丢弃
+func (s *slsShipper) send(batch []slsLog) {
+	backoff := 200 * time.Millisecond
+	for attempt := 0; ; attempt++ {
+		err := s.client.putLogs(batch)
+		if err == nil {
+			slsLogsSentTotal.Add(float64(len(batch)))
+			return
+		}
+
+		if !retryable(err) || attempt >= s.maxRetries || s.closed() {
+			slsLogsDroppedTotal.WithLabelValues(slsDropSendFailed).Add(float64(len(batch)))
+			s.report.Error("投递日志到SLS失败",
+				zap.Int("count", len(batch)),
+				zap.Int("attempts", attempt+1),
+				zap.Error(err))
+			return
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-s.closing:
+		}
+		backoff *= 2
+	}
+}
+
+// closed 是否正在关闭，关闭时不再等待重试
+func (s *slsShipper) closed() bool {
+	select {
+	case <-s.closing:
+		return true
+	default:
+		return false
+	}
+}
+
+// slsError SLS返回的错误
+type slsError struct {
+	StatusCode int
+	Code       string `json:"errorCode"`
+	Message    string `json:"errorMessage"`
+}
+
+func (e *slsError) Error() string {
+	return fmt.Sprintf("sls: %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// retryable 限流、服务端错误和网络错误可重试，签名、参数等错误重试无意义
+func retryable(err error) bool {
+	if e, ok := err.(*slsError); ok {
+		return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+	}
+	return true
+}
+
+// slsClient SLS PutLogs 接口客户端
+type slsClient struct {
+	url             string
+	resource        string
+	accessKeyID     string
+	accessKeySecret string
+	topic           string
+	source          string
+	httpClient      *http.Client
+}
+
+// newSLSClient 创建客户端，endpoint 带协议时原样使用，否则按 https://{project}.{endpoint} 访问
+func newSLSClient(cfg config.AliyunConfig) *slsClient {
+	base := strings.TrimRight(cfg.SLS.Endpoint, "/")
+	if !strings.Contains(base, "://") {
+		base = "https://" + cfg.SLS.Project + "." + base
+	}
+
+	source, _ := os.Hostname()
+	resource := "/logstores/" + cfg.SLS.Logstore + "/shards/lb"
+	return &slsClient{
+		url:             base + resource,
+		resource:        resource,
+		accessKeyID:     cfg.AccessKeyID,
+		accessKeySecret: cfg.AccessKeySecret,
+		topic:           cfg.SLS.Topic,
+		source:          source,
+		httpClient:      &http.Client{Timeout: time.Duration(cfg.SLS.Timeout) * time.Second},
+	}
+}
+
+// putLogs 以protobuf编码并deflate压缩后写入logstore
+func (c *slsClient) putLogs(logs []slsLog) error {
+	raw := encodeLogGroup(logs, c.topic, c.source)
+
+	var body bytes.Buffer
+	zw := zlib.NewWriter(&body)
+	if _, err := zw.Write(raw); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(body.Bytes()))
+	if err != nil {
+		return err
+	}
+
+	sum := md5.Sum(body.Bytes())
+	req.Header.Set("Content-Type", slsContentType)
+	req.Header.Set("Content-MD5", strings.ToUpper(hex.EncodeToString(sum[:])))
+	req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-log-apiversion", slsAPIVersion)
+	req.Header.Set("x-log-bodyrawsize", strconv.Itoa(len(raw)))
+	req.Header.Set("x-log-compresstype", "deflate")
+	req.Header.Set("x-log-signaturemethod", slsSignatureMethod)
+	req.Header.Set("Authorization", "LOG "+c.accessKeyID+":"+c.sign(req))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	e := &slsError{StatusCode: resp.StatusCode}
+	if b, err := io.ReadAll(io.LimitReader(resp.Body, 4096)); err == nil {
+		_ = json.Unmarshal(b, e)
+	}
+	return e
+}
+
+// sign 按SLS签名规则计算签名：
+// VERB\nCONTENT-MD5\nCONTENT-TYPE\nDATE\nCanonicalizedLOGHeaders\nCanonicalizedResource
+func (c *slsClient) sign(req *http.Request) string {
+	var headers []string
+	for key, values := range req.Header {
+		key = strings.ToLower(key)
+		if strings.HasPrefix(key, "x-log-") || strings.HasPrefix(key, "x-acs-") {
+			headers = append(headers, key+":"+strings.Join(values, ","))
+		}
+	}
+	sort.Strings(headers)
+
+	stringToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		req.Header.Get("Date"),
+		strings.Join(headers, "\n"),
+		c.resource,
+	}, "\n")
+
+	mac := hmac.New(sha1.New, []byte(c.accessKeySecret))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// encodeLogGroup 按SLS的LogGroup protobuf定义编码：
+// LogGroup{Logs=1, Topic=3, Source=4}，Log{Time=1, Contents=2, TimeNs=4}，Content{Key=1, Value=2}
+func encodeLogGroup(logs []slsLog, topic, source string) []byte {
+	var b []byte
+	for _, log := range logs {
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, encodeLog(log))
+	}
+	if topic != "" {
+		b = protowire.AppendTag(b, 3, protowire.BytesType)
+		b = protowire.AppendString(b, topic)
+	}
+	if source != "" {
+		b = protowire.AppendTag(b, 4, protowire.BytesType)
+		b = protowire.AppendString(b, source)
+	}
+	return b
+}
+
+func encodeLog(log slsLog) []byte {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(log.time.Unix()))
+	for _, kv := range log.contents {
+		var content []byte
+		content = protowire.AppendTag(content, 1, protowire.BytesType)
+		content = protowire.AppendString(content, kv[0])
+		content = protowire.AppendTag(content, 2, protowire.BytesType)
+		content = protowire.AppendString(content, kv[1])
+
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendBytes(b, content)
+	}
+	b = protowire.AppendTag(b, 4, protowire.Fixed32Type)
+	b = protowire.AppendFixed32(b, uint32(log.time.Nanosecond()))
+	return b
+}
+
+// sls 已开启的SLS投递core，未开启时为nil
+var sls *slsCore
+
+// InitSLS 开启日志投递到阿里云SLS，需在Init之后调用；本地日志不受影响
+func InitSLS(cfg config.AliyunConfig) {
+	if !cfg.SLS.Enabled {
+		return
+	}
+
+	report := zap.New(&levelCore{Core: local, enabler: level}, options...).Named("sls")
+	shipper := newSLSShipper(cfg.SLS, newSLSClient(cfg), report)
+	sls = &slsCore{LevelEnabler: parseLevel(cfg.SLS.Level), shipper: shipper}
+	build()
+
+	Info("日志投递到SLS已开启",
+		zap.String("project", cfg.SLS.Project),
+		zap.String("logstore", cfg.SLS.Logstore),
+		zap.String("min_level", cfg.SLS.Level))
+}
+
+// CloseSLS 投递剩余日志后停止投递，之后的日志只写本地
+func CloseSLS(ctx context.Context) error {
+	if sls == nil {
+		return nil
+	}
+	return sls.shipper.close(ctx)
+}
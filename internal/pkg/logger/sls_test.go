@@ -0,0 +1,285 @@
+package logger
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ocean-marketing/internal/config"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	testAccessKeyID     = "test-key-id"
+	testAccessKeySecret = "test-key-secret"
+)
+
+// newTestSLSClient 创建指向 httptest 服务的客户端
+func newTestSLSClient(url string) *slsClient {
+	return newSLSClient(config.AliyunConfig{
+		AccessKeyID:     testAccessKeyID,
+		AccessKeySecret: testAccessKeySecret,
+		SLS: config.SLSConfig{
+			Endpoint: url,
+			Project:  "test-project",
+			Logstore: "app-log",
+			Topic:    "ocean-marketing",
+			Timeout:  5,
+		},
+	})
+}
+
+// expectedSignature 按SLS文档独立计算签名
+func expectedSignature(r *http.Request) string {
+	stringToSign := r.Method + "\n" +
+		r.Header.Get("Content-MD5") + "\n" +
+		r.Header.Get("Content-Type") + "\n" +
+		r.Header.Get("Date") + "\n" +
+		"x-log-apiversion:" + r.Header.Get("x-log-apiversion") + "\n" +
+		"x-log-bodyrawsize:" + r.Header.Get("x-log-bodyrawsize") + "\n" +
+		"x-log-compresstype:" + r.Header.Get("x-log-compresstype") + "\n" +
+		"x-log-signaturemethod:" + r.Header.Get("x-log-signaturemethod") + "\n" +
+		r.URL.Path
+
+	mac := hmac.New(sha1.New, []byte(testAccessKeySecret))
+	mac.Write([]byte(stringToSign))
+	return "LOG " + testAccessKeyID + ":" + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// decodedLogGroup 解码后的LogGroup
+type decodedLogGroup struct {
+	topic  string
+	source string
+	logs   []map[string]string
+	times  []uint64
+}
+
+// decodeLogGroup 按LogGroup的protobuf定义解码请求体
+func decodeLogGroup(t *testing.T, b []byte) decodedLogGroup {
+	t.Helper()
+
+	var group decodedLogGroup
+	forEachField(t, b, func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) {
+		switch num {
+		case 1:
+			contents := make(map[string]string)
+			forEachField(t, value, func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) {
+				switch num {
+				case 1:
+					group.times = append(group.times, varint)
+				case 2:
+					var key, val string
+					forEachField(t, value, func(num protowire.Number, _ protowire.Type, value []byte, _ uint64) {
+						if num == 1 {
+							key = string(value)
+						} else if num == 2 {
+							val = string(value)
+						}
+					})
+					contents[key] = val
+				}
+			})
+			group.logs = append(group.logs, contents)
+		case 3:
+			group.topic = string(value)
+		case 4:
+			group.source = string(value)
+		}
+	})
+	return group
+}
+
+// forEachField 遍历protobuf消息的字段
+func forEachField(t *testing.T, b []byte, fn func(num protowire.Number, typ protowire.Type, value []byte, varint uint64)) {
+	t.Helper()
+
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			t.Fatalf("invalid tag: %v", protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		switch typ {
+		case protowire.BytesType:
+			value, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				t.Fatalf("invalid bytes field %d: %v", num, protowire.ParseError(n))
+			}
+			fn(num, typ, value, 0)
+			b = b[n:]
+		case protowire.VarintType:
+			value, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				t.Fatalf("invalid varint field %d: %v", num, protowire.ParseError(n))
+			}
+			fn(num, typ, nil, value)
+			b = b[n:]
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				t.Fatalf("invalid field %d: %v", num, protowire.ParseError(n))
+			}
+			b = b[n:]
+		}
+	}
+}
+
+func TestSLSClientPutLogs(t *testing.T) {
+	now := time.Unix(1700000000, 123)
+	logs := []slsLog{
+		{time: now, contents: [][2]string{{"level", "info"}, {"msg", "启动完成"}}},
+		{time: now.Add(time.Second), contents: [][2]string{{"level", "error"}, {"msg", "投递失败"}}},
+	}
+
+	var got decodedLogGroup
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		if want := "/logstores/app-log/shards/lb"; r.URL.Path != want {
+			t.Errorf("path = %s, want %s", r.URL.Path, want)
+		}
+		if got, want := r.Header.Get("Authorization"), expectedSignature(r); got != want {
+			t.Errorf("Authorization = %s, want %s", got, want)
+		}
+		if got := r.Header.Get("Content-Type"); got != slsContentType {
+			t.Errorf("Content-Type = %s, want %s", got, slsContentType)
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("read body: %v", err)
+		}
+		sum := md5.Sum(body)
+		if got, want := r.Header.Get("Content-MD5"), strings.ToUpper(hex.EncodeToString(sum[:])); got != want {
+			t.Errorf("Content-MD5 = %s, want %s", got, want)
+		}
+
+		zr, err := zlib.NewReader(bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("zlib: %v", err)
+		}
+		raw, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("zlib: %v", err)
+		}
+		if got := r.Header.Get("x-log-bodyrawsize"); got != strconv.Itoa(len(raw)) {
+			t.Errorf("x-log-bodyrawsize = %s, want %d", got, len(raw))
+		}
+
+		got = decodeLogGroup(t, raw)
+	}))
+	defer server.Close()
+
+	client := newTestSLSClient(server.URL)
+	if err := client.putLogs(logs); err != nil {
+		t.Fatalf("putLogs: %v", err)
+	}
+
+	if got.topic != "ocean-marketing" {
+		t.Errorf("topic = %q, want ocean-marketing", got.topic)
+	}
+	if got.source != client.source {
+		t.Errorf("source = %q, want %q", got.source, client.source)
+	}
+	if len(got.logs) != len(logs) {
+		t.Fatalf("logs = %d, want %d", len(got.logs), len(logs))
+	}
+	for i, log := range logs {
+		if got.times[i] != uint64(log.time.Unix()) {
+			t.Errorf("logs[%d] time = %d, want %d", i, got.times[i], log.time.Unix())
+		}
+		for _, kv := range log.contents {
+			if got.logs[i][kv[0]] != kv[1] {
+				t.Errorf("logs[%d][%s] = %q, want %q", i, kv[0], got.logs[i][kv[0]], kv[1])
+			}
+		}
+	}
+}
+
+func TestSLSShipperRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		failures     int
+		wantRequests int
+		wantSent     float64
+		wantDropped  float64
+	}{
+		{name: "5xx后重试成功", status: http.StatusInternalServerError, failures: 2, wantRequests: 3, wantSent: 1},
+		{name: "限流后重试成功", status: http.StatusTooManyRequests, failures: 1, wantRequests: 2, wantSent: 1},
+		{name: "重试耗尽后丢弃", status: http.StatusServiceUnavailable, failures: 10, wantRequests: 3, wantDropped: 1},
+		{name: "签名错误不重试", status: http.StatusUnauthorized, failures: 10, wantRequests: 1, wantDropped: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if int(requests.Add(1)) <= tt.failures {
+					w.WriteHeader(tt.status)
+					_, _ = w.Write([]byte(`{"errorCode":"TestError","errorMessage":"test"}`))
+				}
+			}))
+			defer server.Close()
+
+			sentBefore := testutil.ToFloat64(slsLogsSentTotal)
+			droppedBefore := testutil.ToFloat64(slsLogsDroppedTotal.WithLabelValues(slsDropSendFailed))
+
+			shipper := newSLSShipper(config.SLSConfig{
+				BatchSize:     10,
+				FlushInterval: 1000,
+				QueueSize:     10,
+				MaxRetries:    2,
+			}, newTestSLSClient(server.URL), zap.NewNop())
+			shipper.enqueue(slsLog{time: time.Now(), contents: [][2]string{{"msg", "test"}}})
+			shipper.flush()
+
+			if got := int(requests.Load()); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+			if got := testutil.ToFloat64(slsLogsSentTotal) - sentBefore; got != tt.wantSent {
+				t.Errorf("sent = %v, want %v", got, tt.wantSent)
+			}
+			if got := testutil.ToFloat64(slsLogsDroppedTotal.WithLabelValues(slsDropSendFailed)) - droppedBefore; got != tt.wantDropped {
+				t.Errorf("dropped = %v, want %v", got, tt.wantDropped)
+			}
+		})
+	}
+}
+
+func TestSLSShipperQueueFull(t *testing.T) {
+	// 不启动投递循环，队列只能容纳一条
+	shipper := &slsShipper{
+		queue:   make(chan slsLog, 1),
+		closing: make(chan struct{}),
+	}
+
+	before := testutil.ToFloat64(slsLogsDroppedTotal.WithLabelValues(slsDropQueueFull))
+	for i := 0; i < 3; i++ {
+		shipper.enqueue(slsLog{time: time.Now()})
+	}
+
+	if got := testutil.ToFloat64(slsLogsDroppedTotal.WithLabelValues(slsDropQueueFull)) - before; got != 2 {
+		t.Errorf("dropped = %v, want 2", got)
+	}
+	if got := len(shipper.queue); got != 1 {
+		t.Errorf("queue = %d, want 1", got)
+	}
+}
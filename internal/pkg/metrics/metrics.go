@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Namespace 业务指标的统一前缀，与HTTP、数据库等基础指标区分
+const Namespace = "business"
+
+// namePattern 指标名只允许小写字母、数字和下划线
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// MustRegister 注册到默认注册表并返回，同名同标签的指标已注册时返回已注册的实例，
+// 便于在多处声明同一指标；名称相同但标签或类型不一致时panic
+func MustRegister[T prometheus.Collector](c T) T {
+	if err := prometheus.Register(c); err != nil {
+		var registered prometheus.AlreadyRegisteredError
+		if errors.As(err, &registered) {
+			if existing, ok := registered.ExistingCollector.(T); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+	return c
+}
+
+// Counter 声明业务计数器，名称须以 _total 结尾，如 Counter("examples_created_total", "...") 导出为 business_examples_created_total
+func Counter(name, help string, labels ...string) *prometheus.CounterVec {
+	checkName(name)
+	if !strings.HasSuffix(name, "_total") {
+		panic(fmt.Sprintf("metrics: counter %q must end with _total", name))
+	}
+	return MustRegister(prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      name,
+			Help:      help,
+		},
+		labels,
+	))
+}
+
+// Gauge 声明业务指标的当前值，如排队中的任务数
+func Gauge(name, help string, labels ...string) *prometheus.GaugeVec {
+	checkName(name)
+	return MustRegister(prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      name,
+			Help:      help,
+		},
+		labels,
+	))
+}
+
+// Histogram 声明业务分布指标，buckets 为空时使用 prometheus.DefBuckets
+func Histogram(name, help string, buckets []float64, labels ...string) *prometheus.HistogramVec {
+	checkName(name)
+	return MustRegister(prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      name,
+			Help:      help,
+			Buckets:   buckets,
+		},
+		labels,
+	))
+}
+
+// checkName 指标名不符合规范时panic，在声明时（通常是包初始化）即可发现
+func checkName(name string) {
+	if !namePattern.MatchString(name) {
+		panic(fmt.Sprintf("metrics: invalid metric name %q", name))
+	}
+	if strings.HasPrefix(name, Namespace+"_") {
+		panic(fmt.Sprintf("metrics: metric name %q must not include the %s_ prefix", name, Namespace))
+	}
+}
@@ -15,6 +15,8 @@ func AutoMigrate() error {
 	// 自动迁移所有模型
 	err := db.AutoMigrate(
 		&model.Example{},
+		&model.OutboxMessage{},
+		&model.Job{},
 	)
 
 	if err != nil {
@@ -0,0 +1,113 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+
+	"ocean-marketing/internal/model"
+	"ocean-marketing/internal/pkg/eventbus"
+	"ocean-marketing/pkg/mq"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"gorm.io/gorm"
+)
+
+// Event 待发布的事件
+type Event struct {
+	AggregateType string
+	AggregateID   string
+	Type          string
+	// Exchange 为空时由Relay使用配置的 outbox.exchange
+	Exchange string
+	// RoutingKey 为空时使用事件类型
+	RoutingKey string
+	Data       map[string]interface{}
+}
+
+// Add 在业务事务中写入发件箱，tx必须是业务操作所在的事务；
+// tx通过 WithContext 绑定的trace上下文随消息保存，投递时恢复
+func Add(tx *gorm.DB, events ...Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	traceContext, err := encodeTraceContext(tx.Statement.Context)
+	if err != nil {
+		return err
+	}
+
+	messages := make([]*model.OutboxMessage, 0, len(events))
+	for _, event := range events {
+		payload, err := json.Marshal(event.Data)
+		if err != nil {
+			return err
+		}
+
+		messages = append(messages, &model.OutboxMessage{
+			MessageID:     mq.NewMessageID(),
+			AggregateType: event.AggregateType,
+			AggregateID:   event.AggregateID,
+			EventType:     event.Type,
+			Exchange:      event.Exchange,
+			RoutingKey:    event.RoutingKey,
+			Payload:       string(payload),
+			TraceContext:  traceContext,
+			Status:        model.OutboxStatusPending,
+		})
+	}
+
+	return tx.Create(&messages).Error
+}
+
+// FromEvents 将领域事件转换为发件箱事件
+func FromEvents(events ...eventbus.Event) ([]Event, error) {
+	result := make([]Event, 0, len(events))
+	for _, event := range events {
+		data, err := eventbus.EventData(event)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, Event{
+			AggregateType: event.AggregateType(),
+			AggregateID:   event.AggregateID(),
+			Type:          event.EventType(),
+			Data:          data,
+		})
+	}
+	return result, nil
+}
+
+// encodeTraceContext 把ctx中的trace上下文编码为JSON，没有trace时返回空字符串
+func encodeTraceContext(ctx context.Context) (string, error) {
+	if ctx == nil {
+		return "", nil
+	}
+
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return "", nil
+	}
+
+	body, err := json.Marshal(carrier)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// decodeTraceContext 从保存的JSON恢复trace上下文
+func decodeTraceContext(traceContext string) context.Context {
+	ctx := context.Background()
+	if traceContext == "" {
+		return ctx
+	}
+
+	carrier := propagation.MapCarrier{}
+	if err := json.Unmarshal([]byte(traceContext), &carrier); err != nil {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, carrier)
+}
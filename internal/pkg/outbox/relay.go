@@ -0,0 +1,316 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	"ocean-marketing/internal/config"
+	"ocean-marketing/internal/model"
+	"ocean-marketing/internal/pkg/alert"
+	"ocean-marketing/internal/pkg/logger"
+	"ocean-marketing/internal/pkg/redis"
+	"ocean-marketing/pkg/mq"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// logModule 模块日志名，级别可通过 log.modules.outbox 单独调整
+const logModule = "outbox"
+
+// relayLockKey 多副本部署时只有持有锁的实例投递，保证同一聚合的消息有序
+const relayLockKey = "outbox:relay:lock"
+
+// releaseLockScript 只释放自己持有的锁
+const releaseLockScript = `
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+end
+return 0`
+
+// renewLockScript 只续约自己持有的锁
+const renewLockScript = `
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("pexpire", KEYS[1], ARGV[2])
+end
+return 0`
+
+// Relay 发件箱投递器，轮询待投递消息并发布到MQ（至少一次语义）
+type Relay struct {
+	db     *gorm.DB
+	client *mq.Client
+	cfg    config.OutboxConfig
+	token  string
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewRelay 创建投递器
+func NewRelay(db *gorm.DB, client *mq.Client, cfg config.OutboxConfig) *Relay {
+	return &Relay{
+		db:     db,
+		client: client,
+		cfg:    cfg,
+		token:  mq.NewMessageID(),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start 启动投递循环
+func (r *Relay) Start() {
+	go r.run()
+	logger.Named(logModule).Info("发件箱投递器启动",
+		zap.String("exchange", r.cfg.Exchange),
+		zap.Int("poll_interval", r.cfg.PollInterval),
+		zap.Int("batch_size", r.cfg.BatchSize))
+}
+
+// Stop 停止投递循环，等待当前批次完成
+func (r *Relay) Stop() {
+	close(r.stop)
+	<-r.done
+	logger.Named(logModule).Info("发件箱投递器已停止")
+}
+
+// run 投递主循环
+func (r *Relay) run() {
+	defer close(r.done)
+
+	pollTicker := time.NewTicker(time.Duration(r.cfg.PollInterval) * time.Second)
+	defer pollTicker.Stop()
+
+	cleanupTicker := time.NewTicker(time.Hour)
+	defer cleanupTicker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-pollTicker.C:
+			r.relayOnce()
+		case <-cleanupTicker.C:
+			r.cleanup()
+		}
+	}
+}
+
+// lockTTL 投递锁的有效期，批次处理期间定期续约
+func (r *Relay) lockTTL() time.Duration {
+	return time.Duration(r.cfg.PollInterval)*time.Second + 30*time.Second
+}
+
+// relayOnce 投递一批待发送消息
+func (r *Relay) relayOnce() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	start := time.Now()
+	ok, err := redis.GetClient().SetNX(ctx, relayLockKey, r.token, r.lockTTL()).Result()
+	if err != nil {
+		logger.Named(logModule).Error("获取发件箱投递锁失败", zap.Error(err))
+		return
+	}
+	if !ok {
+		return
+	}
+
+	// 续约投递锁，锁被其他实例持有时取消ctx
+	var lockedUntil atomic.Int64
+	lockedUntil.Store(start.Add(r.lockTTL()).UnixNano())
+	heartbeatDone := make(chan struct{})
+	go r.heartbeat(ctx, cancel, &lockedUntil, heartbeatDone)
+	defer func() {
+		cancel()
+		<-heartbeatDone
+		redis.GetClient().Eval(context.Background(), releaseLockScript, []string{relayLockKey}, r.token)
+	}()
+
+	// 每批只取各聚合最早的一条待投递消息，投递成功后继续下一批，直到没有可投递的消息
+	for {
+		messages, err := r.fetchHeads()
+		if err != nil {
+			logger.Named(logModule).Error("查询待投递消息失败", zap.Error(err))
+			return
+		}
+		if len(messages) == 0 {
+			return
+		}
+
+		delivered := 0
+		for i := range messages {
+			message := &messages[i]
+
+			// 锁已丢失或即将过期（续约持续失败）时停止本批次，避免与其他实例并行投递打乱顺序
+			if ctx.Err() != nil || time.Until(time.Unix(0, lockedUntil.Load())) < r.lockTTL()/3 {
+				logger.Named(logModule).Warn("发件箱投递锁已失效，停止本批次", zap.String("message_id", message.MessageID))
+				return
+			}
+
+			if err := r.deliver(message); err != nil {
+				r.markFailedAttempt(message, err)
+				continue
+			}
+
+			now := time.Now()
+			if err := r.db.Model(message).Updates(map[string]interface{}{
+				"status":       model.OutboxStatusDelivered,
+				"attempts":     message.Attempts + 1,
+				"last_error":   "",
+				"delivered_at": &now,
+			}).Error; err != nil {
+				// 消息已发布但状态未更新，下次会重复投递，由消费方按消息ID去重
+				logger.Named(logModule).Error("更新发件箱消息状态失败", zap.Error(err), zap.String("message_id", message.MessageID))
+				continue
+			}
+			delivered++
+		}
+
+		// 本批全部失败时等待下次轮询，避免对失败的消息连续重试
+		if delivered == 0 || r.stopping() {
+			return
+		}
+	}
+}
+
+// fetchHeads 查询各聚合最早的待投递消息；同一聚合存在更早的待投递或失败消息时不取，
+// 失败的消息需人工处理（重置为待投递或标记为已投递）后，该聚合的后续消息才会继续投递
+func (r *Relay) fetchHeads() ([]model.OutboxMessage, error) {
+	var messages []model.OutboxMessage
+	err := r.db.Where("status = ?", model.OutboxStatusPending).
+		Where(`NOT EXISTS (SELECT 1 FROM outbox_messages prev
+			WHERE prev.aggregate_type = outbox_messages.aggregate_type
+			AND prev.aggregate_id = outbox_messages.aggregate_id
+			AND prev.id < outbox_messages.id
+			AND prev.status IN ?)`, []int{model.OutboxStatusPending, model.OutboxStatusFailed}).
+		Order("id").
+		Limit(r.cfg.BatchSize).
+		Find(&messages).Error
+	return messages, err
+}
+
+// stopping 投递器是否正在停止
+func (r *Relay) stopping() bool {
+	select {
+	case <-r.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// heartbeat 定期续约投递锁，成功时更新锁的到期时间，锁已不属于本实例时取消ctx
+func (r *Relay) heartbeat(ctx context.Context, cancel context.CancelFunc, lockedUntil *atomic.Int64, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(r.lockTTL() / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			start := time.Now()
+			renewed, err := redis.GetClient().Eval(ctx, renewLockScript, []string{relayLockKey},
+				r.token, r.lockTTL().Milliseconds()).Int()
+			if err != nil {
+				logger.Named(logModule).Error("发件箱投递锁续约失败", zap.Error(err))
+				continue
+			}
+			if renewed == 0 {
+				logger.Named(logModule).Warn("发件箱投递锁已被其他实例持有，停止投递")
+				cancel()
+				return
+			}
+			lockedUntil.Store(start.Add(r.lockTTL()).UnixNano())
+		}
+	}
+}
+
+// deliver 发布单条消息，发布span挂在写入发件箱时的trace下
+func (r *Relay) deliver(message *model.OutboxMessage) error {
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(message.Payload), &data); err != nil {
+		return err
+	}
+
+	exchange := message.Exchange
+	if exchange == "" {
+		exchange = r.cfg.Exchange
+	}
+	routingKey := message.RoutingKey
+	if routingKey == "" {
+		routingKey = message.EventType
+	}
+
+	return r.client.Publish(decodeTraceContext(message.TraceContext), exchange, routingKey, mq.Message{
+		ID:   message.MessageID,
+		Type: message.EventType,
+		Data: data,
+	})
+}
+
+// markFailedAttempt 记录投递失败，超过最大次数后标记为失败
+func (r *Relay) markFailedAttempt(message *model.OutboxMessage, deliverErr error) {
+	attempts := message.Attempts + 1
+	status := model.OutboxStatusPending
+	if attempts >= r.cfg.MaxAttempts {
+		status = model.OutboxStatusFailed
+		logger.Named(logModule).Error("发件箱消息投递失败次数超限，同一聚合的后续消息暂停投递",
+			zap.Error(deliverErr),
+			zap.String("message_id", message.MessageID),
+			zap.String("event_type", message.EventType),
+			zap.Int("attempts", attempts))
+		alert.Send(context.Background(), alert.Alert{
+			Severity: alert.SeverityWarning,
+			Title:    "发件箱消息投递失败: " + message.EventType,
+			Content:  deliverErr.Error(),
+			Fields: []alert.Field{
+				alert.F("消息ID", message.MessageID),
+				alert.F("聚合", message.AggregateType+":"+message.AggregateID),
+				alert.F("投递次数", attempts),
+			},
+		})
+	} else {
+		logger.Named(logModule).Warn("发件箱消息投递失败",
+			zap.Error(deliverErr),
+			zap.String("message_id", message.MessageID),
+			zap.Int("attempts", attempts))
+	}
+
+	lastError := deliverErr.Error()
+	if len(lastError) > 512 {
+		lastError = lastError[:512]
+	}
+
+	if err := r.db.Model(message).Updates(map[string]interface{}{
+		"status":     status,
+		"attempts":   attempts,
+		"last_error": lastError,
+	}).Error; err != nil {
+		logger.Named(logModule).Error("更新发件箱消息状态失败", zap.Error(err), zap.String("message_id", message.MessageID))
+	}
+}
+
+// cleanup 清理超过保留期的已投递消息
+func (r *Relay) cleanup() {
+	if r.cfg.Retention <= 0 {
+		return
+	}
+
+	before := time.Now().Add(-time.Duration(r.cfg.Retention) * time.Second)
+	result := r.db.Where("status = ? AND delivered_at < ?", model.OutboxStatusDelivered, before).
+		Delete(&model.OutboxMessage{})
+	if result.Error != nil {
+		logger.Named(logModule).Error("清理已投递消息失败", zap.Error(result.Error))
+		return
+	}
+
+	if result.RowsAffected > 0 {
+		logger.Named(logModule).Info("清理已投递消息", zap.Int64("count", result.RowsAffected))
+	}
+}
@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/ulule/limiter/v3"
+	"github.com/ulule/limiter/v3/drivers/store/common"
+)
+
+// incrScript 计数加一，首次写入时设置窗口过期时间，返回 {计数, 剩余毫秒}
+var incrScript = redis.NewScript(`
+local count = redis.call("INCRBY", KEYS[1], ARGV[1])
+if count == tonumber(ARGV[1]) then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	return {count, tonumber(ARGV[2])}
+end
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	ttl = tonumber(ARGV[2])
+end
+return {count, ttl}
+`)
+
+// peekScript 读取计数和剩余毫秒，不修改计数
+var peekScript = redis.NewScript(`
+local count = redis.call("GET", KEYS[1])
+if count == false then
+	return {0, 0}
+end
+return {tonumber(count), redis.call("PTTL", KEYS[1])}
+`)
+
+var _ limiter.Store = (*RedisStore)(nil)
+
+// RedisStore 基于Redis的 limiter.Store 实现，多个实例共享同一份计数
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore 创建Redis限流存储，prefix 为键前缀
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Get 计数加一并返回当前限流状态
+func (s *RedisStore) Get(ctx context.Context, key string, rate limiter.Rate) (limiter.Context, error) {
+	return s.Increment(ctx, key, 1, rate)
+}
+
+// Peek 返回当前限流状态，不计数
+func (s *RedisStore) Peek(ctx context.Context, key string, rate limiter.Rate) (limiter.Context, error) {
+	values, err := peekScript.Run(ctx, s.client, []string{s.key(key)}).Int64Slice()
+	if err != nil {
+		return limiter.Context{}, err
+	}
+	return s.context(rate, values), nil
+}
+
+// Reset 清空计数
+func (s *RedisStore) Reset(ctx context.Context, key string, rate limiter.Rate) (limiter.Context, error) {
+	if err := s.client.Del(ctx, s.key(key)).Err(); err != nil {
+		return limiter.Context{}, err
+	}
+	return common.GetContextFromState(time.Now(), rate, time.Now().Add(rate.Period), 0), nil
+}
+
+// Increment 计数增加 count 并返回当前限流状态
+func (s *RedisStore) Increment(ctx context.Context, key string, count int64, rate limiter.Rate) (limiter.Context, error) {
+	values, err := incrScript.Run(ctx, s.client, []string{s.key(key)}, count, rate.Period.Milliseconds()).Int64Slice()
+	if err != nil {
+		return limiter.Context{}, err
+	}
+	return s.context(rate, values), nil
+}
+
+// context 根据 {计数, 剩余毫秒} 生成限流状态
+func (s *RedisStore) context(rate limiter.Rate, values []int64) limiter.Context {
+	now := time.Now()
+	expiration := now.Add(rate.Period)
+	if len(values) == 2 && values[1] > 0 {
+		expiration = now.Add(time.Duration(values[1]) * time.Millisecond)
+	}
+	return common.GetContextFromState(now, rate, expiration, values[0])
+}
+
+// key 拼接完整的Redis键
+func (s *RedisStore) key(key string) string {
+	return s.prefix + ":" + key
+}
@@ -2,6 +2,7 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 	"ocean-marketing/internal/pkg/logger"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
 
@@ -16,11 +18,18 @@ var Client *redis.Client
 
 // Init 初始化Redis连接
 func Init(cfg config.RedisConfig) {
+	addr := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
 	Client = redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
-		Password: cfg.Password,
-		DB:       cfg.DB,
+		Addr:         addr,
+		Password:     cfg.Password,
+		DB:           cfg.DB,
+		PoolSize:     cfg.PoolSize,
+		MinIdleConns: cfg.MinIdleConns,
 	})
+	Client.AddHook(tracingHook{addr: addr})
+
+	// 连接池指标
+	prometheus.MustRegister(newPoolStatsCollector(Client, addr))
 
 	// 测试连接
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -38,6 +47,14 @@ func GetClient() *redis.Client {
 	return Client
 }
 
+// Ping 检查Redis连接
+func Ping(ctx context.Context) error {
+	if Client == nil {
+		return errors.New("redis: not initialized")
+	}
+	return Client.Ping(ctx).Err()
+}
+
 // Close 关闭Redis连接
 func Close() error {
 	if Client != nil {
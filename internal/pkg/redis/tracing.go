@@ -0,0 +1,96 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"ocean-marketing/internal/pkg/tracer"
+
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracingHook 为每条命令和管道创建子span，上下文中没有span时不记录
+type tracingHook struct {
+	addr string
+}
+
+var _ redis.Hook = tracingHook{}
+
+// BeforeProcess 开始命令span
+func (h tracingHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, nil
+	}
+
+	ctx, _ = tracer.StartSpan(ctx, "redis."+cmd.FullName(),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(h.attributes(cmd.FullName())...),
+	)
+	return ctx, nil
+}
+
+// AfterProcess 结束命令span
+func (h tracingHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	h.end(ctx, cmd.Err())
+	return nil
+}
+
+// BeforeProcessPipeline 开始管道span
+func (h tracingHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, nil
+	}
+
+	names := make([]string, 0, len(cmds))
+	for _, cmd := range cmds {
+		names = append(names, cmd.FullName())
+	}
+
+	ctx, _ = tracer.StartSpan(ctx, "redis.pipeline",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(h.attributes(strings.Join(names, " "))...),
+		trace.WithAttributes(attribute.Int("db.redis.num_cmd", len(cmds))),
+	)
+	return ctx, nil
+}
+
+// AfterProcessPipeline 结束管道span
+func (h tracingHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if cmdErr := cmd.Err(); cmdErr != nil {
+			err = cmdErr
+			break
+		}
+	}
+	h.end(ctx, err)
+	return nil
+}
+
+// attributes span的公共属性，只记录命令名不记录参数
+func (h tracingHook) attributes(operation string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.DBSystemRedis,
+		semconv.DBOperation(operation),
+		semconv.ServerAddress(h.addr),
+	}
+}
+
+// end 记录错误后结束span，键不存在不视为错误
+func (h tracingHook) end(ctx context.Context, err error) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	if err != nil && !errors.Is(err, redis.Nil) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
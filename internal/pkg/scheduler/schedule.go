@@ -0,0 +1,44 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Schedule 计算下一次触发时间
+type Schedule interface {
+	Next(time.Time) time.Time
+}
+
+// intervalSchedule 固定间隔，按Unix纪元对齐，保证各副本计算出的触发时间一致
+type intervalSchedule struct {
+	interval time.Duration
+}
+
+// Next 下一个对齐的触发时间
+func (s intervalSchedule) Next(t time.Time) time.Time {
+	return t.Truncate(s.interval).Add(s.interval)
+}
+
+// Parse 解析调度表达式：标准5段cron表达式、@daily等描述符，或 "@every 10m" 固定间隔
+func Parse(spec string) (Schedule, error) {
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("scheduler: invalid interval %q: %w", spec, err)
+		}
+		if interval < time.Second {
+			return nil, fmt.Errorf("scheduler: interval %q must be at least 1s", spec)
+		}
+		return intervalSchedule{interval: interval}, nil
+	}
+
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("scheduler: invalid cron spec %q: %w", spec, err)
+	}
+	return schedule, nil
+}
@@ -0,0 +1,50 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	base := time.Date(2024, 3, 15, 10, 7, 30, 0, time.Local)
+
+	tests := []struct {
+		name    string
+		spec    string
+		from    time.Time
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "每5分钟", spec: "*/5 * * * *", from: base, want: time.Date(2024, 3, 15, 10, 10, 0, 0, time.Local)},
+		{name: "每天凌晨3点", spec: "0 3 * * *", from: base, want: time.Date(2024, 3, 16, 3, 0, 0, 0, time.Local)},
+		{name: "工作日9点", spec: "0 9 * * 1-5", from: time.Date(2024, 3, 15, 10, 0, 0, 0, time.Local), want: time.Date(2024, 3, 18, 9, 0, 0, 0, time.Local)},
+		{name: "描述符", spec: "@daily", from: base, want: time.Date(2024, 3, 16, 0, 0, 0, 0, time.Local)},
+		{name: "固定间隔按纪元对齐", spec: "@every 10m", from: time.Unix(1710497250, 0), want: time.Unix(1710497400, 0)},
+		{name: "固定间隔在边界上取下一个", spec: "@every 1h", from: time.Unix(7200, 0), want: time.Unix(10800, 0)},
+		{name: "间隔两侧空白", spec: "@every  30s ", from: time.Unix(100, 0), want: time.Unix(120, 0)},
+		{name: "不支持秒字段", spec: "0 */5 * * * *", wantErr: true},
+		{name: "字段超出范围", spec: "0 25 * * *", wantErr: true},
+		{name: "无效表达式", spec: "every day", wantErr: true},
+		{name: "无效间隔", spec: "@every ten", wantErr: true},
+		{name: "间隔小于1秒", spec: "@every 500ms", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Parse(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse(%q) error = nil, want error", tt.spec)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.spec, err)
+			}
+
+			if got := schedule.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.from, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"ocean-marketing/internal/config"
+	"ocean-marketing/internal/pkg/logger"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+// 错过执行（所有副本都不在线）后的处理策略
+const (
+	// MissedSkip 跳过错过的执行，等待下一次触发
+	MissedSkip = "skip"
+	// MissedRunOnce 启动后立即补执行一次
+	MissedRunOnce = "run_once"
+)
+
+const defaultTimeout = 10 * time.Minute
+
+// logModule 模块日志名，级别可通过 log.modules.scheduler 单独调整
+const logModule = "scheduler"
+
+var (
+	// 定时任务执行次数
+	taskRunsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "scheduler_task_runs_total",
+			Help: "Total number of scheduled task executions",
+		},
+		[]string{"task", "result"},
+	)
+
+	// 定时任务执行耗时
+	taskDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "scheduler_task_duration_seconds",
+			Help:    "Duration of scheduled task executions in seconds",
+			Buckets: prometheus.ExponentialBuckets(0.01, 4, 10),
+		},
+		[]string{"task"},
+	)
+
+	// 最近一次成功执行的时间
+	taskLastSuccess = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "scheduler_task_last_success_timestamp_seconds",
+			Help: "Unix timestamp of the last successful execution",
+		},
+		[]string{"task"},
+	)
+
+	// 错过的执行次数
+	taskMissedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "scheduler_task_missed_total",
+			Help: "Total number of detected missed executions",
+		},
+		[]string{"task"},
+	)
+)
+
+// Task 定时任务
+type Task struct {
+	Name string
+	// Spec 5段cron表达式、@daily等描述符或 "@every 10m"
+	Spec string
+	// Timeout 单次执行超时，默认10分钟
+	Timeout time.Duration
+	// MissedPolicy 错过执行后的策略，默认skip
+	MissedPolicy string
+	Run          func(ctx context.Context) error
+}
+
+var (
+	mu    sync.Mutex
+	tasks []Task
+)
+
+// Register 注册定时任务，需在Scheduler.Start之前调用
+func Register(task Task) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	tasks = append(tasks, task)
+}
+
+// Scheduler 定时任务调度器，通过Redis按触发时间加锁，每次触发在所有副本中只执行一次
+type Scheduler struct {
+	cfg   config.SchedulerConfig
+	rdb   *redis.Client
+	token string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New 创建调度器
+func New(cfg config.SchedulerConfig, rdb *redis.Client) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		cfg:    cfg,
+		rdb:    rdb,
+		token:  newToken(),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Start 解析所有任务并启动调度，任一表达式无效时返回错误
+func (s *Scheduler) Start() error {
+	mu.Lock()
+	registered := append([]Task{}, tasks...)
+	mu.Unlock()
+
+	type scheduled struct {
+		task     Task
+		schedule Schedule
+	}
+
+	var list []scheduled
+	for _, task := range registered {
+		// 配置覆盖代码中的默认值
+		if override, ok := s.cfg.Tasks[task.Name]; ok {
+			if override.Disabled {
+				logger.Named(logModule).Info("定时任务已禁用", zap.String("task", task.Name))
+				continue
+			}
+			if override.Spec != "" {
+				task.Spec = override.Spec
+			}
+			if override.MissedPolicy != "" {
+				task.MissedPolicy = override.MissedPolicy
+			}
+		}
+
+		if task.MissedPolicy != MissedSkip && task.MissedPolicy != MissedRunOnce {
+			if task.MissedPolicy != "" {
+				return fmt.Errorf("scheduler: task %s has invalid missed policy %q", task.Name, task.MissedPolicy)
+			}
+			task.MissedPolicy = MissedSkip
+		}
+		if task.Timeout <= 0 {
+			task.Timeout = defaultTimeout
+		}
+
+		schedule, err := Parse(task.Spec)
+		if err != nil {
+			return fmt.Errorf("scheduler: task %s: %w", task.Name, err)
+		}
+		list = append(list, scheduled{task: task, schedule: schedule})
+	}
+
+	for _, item := range list {
+		s.wg.Add(1)
+		go s.loop(item.task, item.schedule)
+
+		logger.Named(logModule).Info("定时任务已调度",
+			zap.String("task", item.task.Name),
+			zap.String("spec", item.task.Spec),
+			zap.String("missed_policy", item.task.MissedPolicy))
+	}
+
+	return nil
+}
+
+// Stop 停止调度并等待执行中的任务结束
+func (s *Scheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+	logger.Named(logModule).Info("定时任务调度器已停止")
+}
+
+// loop 单个任务的调度循环
+func (s *Scheduler) loop(task Task, schedule Schedule) {
+	defer s.wg.Done()
+
+	s.catchUp(task, schedule)
+
+	next := schedule.Next(time.Now())
+	for {
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.runTick(task, next)
+
+		// 执行时间超过一个周期时跳到当前时间之后的触发点
+		now := time.Now()
+		next = schedule.Next(next)
+		if next.Before(now) {
+			next = schedule.Next(now)
+		}
+	}
+}
+
+// catchUp 检测上次执行之后是否有错过的触发
+func (s *Scheduler) catchUp(task Task, schedule Schedule) {
+	value, err := s.rdb.Get(s.ctx, lastRunKey(task.Name)).Result()
+	if err != nil {
+		if err != redis.Nil {
+			logger.Named(logModule).Error("读取定时任务上次执行时间失败", zap.Error(err), zap.String("task", task.Name))
+		}
+		return
+	}
+
+	lastUnix, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return
+	}
+
+	missedAt := schedule.Next(time.Unix(lastUnix, 0))
+	if !missedAt.Before(time.Now()) {
+		return
+	}
+
+	taskMissedTotal.WithLabelValues(task.Name).Inc()
+	logger.Named(logModule).Warn("检测到错过的定时任务",
+		zap.String("task", task.Name),
+		zap.Time("missed_at", missedAt),
+		zap.String("missed_policy", task.MissedPolicy))
+
+	if task.MissedPolicy == MissedRunOnce {
+		s.runTick(task, missedAt)
+	}
+}
+
+// runTick 获取该触发时间的锁后执行任务
+func (s *Scheduler) runTick(task Task, tick time.Time) {
+	lockKey := fmt.Sprintf("scheduler:lock:%s:%d", task.Name, tick.Unix())
+	acquired, err := s.rdb.SetNX(s.ctx, lockKey, s.token, task.Timeout+time.Minute).Result()
+	if err != nil {
+		taskRunsTotal.WithLabelValues(task.Name, "lock_error").Inc()
+		logger.Named(logModule).Error("获取定时任务锁失败", zap.Error(err), zap.String("task", task.Name))
+		return
+	}
+	if !acquired {
+		logger.Named(logModule).Debug("定时任务已由其他实例执行", zap.String("task", task.Name), zap.Time("tick", tick))
+		return
+	}
+
+	if err := s.rdb.Set(s.ctx, lastRunKey(task.Name), tick.Unix(), 0).Err(); err != nil {
+		logger.Named(logModule).Error("记录定时任务执行时间失败", zap.Error(err), zap.String("task", task.Name))
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, task.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err = safeRun(ctx, task)
+	duration := time.Since(start)
+
+	taskDuration.WithLabelValues(task.Name).Observe(duration.Seconds())
+
+	fields := []zap.Field{
+		zap.String("task", task.Name),
+		zap.Time("tick", tick),
+		zap.Duration("duration", duration),
+	}
+	if err != nil {
+		taskRunsTotal.WithLabelValues(task.Name, "failure").Inc()
+		logger.Named(logModule).Error("定时任务执行失败", append(fields, zap.Error(err))...)
+		return
+	}
+
+	taskRunsTotal.WithLabelValues(task.Name, "success").Inc()
+	taskLastSuccess.WithLabelValues(task.Name).SetToCurrentTime()
+	logger.Named(logModule).Info("定时任务执行成功", fields...)
+}
+
+// safeRun 执行任务并把panic转换为错误
+func safeRun(ctx context.Context, task Task) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("scheduler: task panic: %v", r)
+		}
+	}()
+
+	return task.Run(ctx)
+}
+
+// lastRunKey 记录任务最近一次触发时间的键
+func lastRunKey(name string) string {
+	return "scheduler:last:" + name
+}
+
+// newToken 生成实例标识
+func newToken() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
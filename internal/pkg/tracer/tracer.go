@@ -1,64 +1,195 @@
 package tracer
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"io"
+	"net"
+	"os"
+	"time"
 
 	"ocean-marketing/internal/config"
 	"ocean-marketing/internal/pkg/logger"
+	"ocean-marketing/internal/pkg/version"
 
-	"github.com/opentracing/opentracing-go"
-	"github.com/uber/jaeger-client-go"
-	jaegerConfig "github.com/uber/jaeger-client-go/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 	"go.uber.org/zap"
 )
 
-var tracer opentracing.Tracer
-var closer io.Closer
+// 导出方式
+const (
+	ExporterOTLPGRPC = "otlp_grpc"
+	ExporterOTLPHTTP = "otlp_http"
+	ExporterStdout   = "stdout"
+	// ExporterMemory 保存在内存中，用于测试
+	ExporterMemory = "memory"
+	ExporterNone   = "none"
+)
+
+// instrumentationName 本服务创建span时使用的instrumentation名称
+const instrumentationName = "ocean-marketing"
+
+var (
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer = noop.NewTracerProvider().Tracer(instrumentationName)
+	memory   *tracetest.InMemoryExporter
+	endpoint string
+)
 
-// Init 初始化链路追踪
+// Init 初始化链路追踪，失败时记录错误并保持不采集
 func Init(cfg config.TracerConfig) {
-	jaegerCfg := jaegerConfig.Configuration{
-		ServiceName: cfg.ServiceName,
-		Sampler: &jaegerConfig.SamplerConfig{
-			Type:  jaeger.SamplerTypeConst,
-			Param: cfg.SampleRate,
-		},
-		Reporter: &jaegerConfig.ReporterConfig{
-			LogSpans:           true,
-			LocalAgentHostPort: fmt.Sprintf("%s:%d", cfg.AgentHost, cfg.AgentPort),
-		},
+	// 无论是否采集都传播上游的trace上下文和baggage
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if !cfg.Enabled || cfg.Exporter == ExporterNone {
+		logger.Info("链路追踪未启用")
+		return
 	}
 
-	var err error
-	tracer, closer, err = jaegerCfg.NewTracer()
+	exporter, err := newExporter(cfg)
 	if err != nil {
 		logger.Error("初始化链路追踪失败", zap.Error(err))
 		return
 	}
 
-	opentracing.SetGlobalTracer(tracer)
-	logger.Info("链路追踪初始化成功", zap.String("service", cfg.ServiceName))
+	InitWithExporter(cfg, exporter)
+	// 只有OTLP导出器需要连接接收端，stdout/memory不参与连通性检查
+	endpoint = ""
+	if cfg.Exporter == ExporterOTLPGRPC || cfg.Exporter == ExporterOTLPHTTP {
+		endpoint = cfg.Endpoint
+	}
+	logger.Info("链路追踪初始化成功",
+		zap.String("service", cfg.ServiceName),
+		zap.String("exporter", cfg.Exporter),
+		zap.Float64("sample_rate", cfg.SampleRate))
+}
+
+// InitWithExporter 使用指定的导出器初始化，便于测试时注入
+func InitWithExporter(cfg config.TracerConfig, exporter sdktrace.SpanExporter) {
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+		semconv.ServiceVersion(version.Version),
+		semconv.DeploymentEnvironment(config.Env()),
+	))
+	if err != nil {
+		res = resource.Default()
+	}
+
+	var spanProcessor sdktrace.SpanProcessor
+	if cfg.Exporter == ExporterMemory || cfg.Exporter == ExporterStdout {
+		// 测试和本地调试时同步导出，结束span后立即可见
+		spanProcessor = sdktrace.NewSimpleSpanProcessor(exporter)
+	} else {
+		spanProcessor = sdktrace.NewBatchSpanProcessor(exporter)
+	}
+
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(newSampler(cfg)),
+		sdktrace.WithSpanProcessor(spanProcessor),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	tracer = provider.Tracer(instrumentationName)
 }
 
-// GetTracer 获取tracer实例
-func GetTracer() opentracing.Tracer {
+// newSampler 按比例采样；parent_based 时遵循上游的采样决定，只对新的trace按比例采样
+func newSampler(cfg config.TracerConfig) sdktrace.Sampler {
+	sampler := sdktrace.TraceIDRatioBased(cfg.SampleRate)
+	if cfg.ParentBased {
+		return sdktrace.ParentBased(sampler)
+	}
+	return sampler
+}
+
+// newExporter 根据配置创建导出器
+func newExporter(cfg config.TracerConfig) (sdktrace.SpanExporter, error) {
+	ctx := context.Background()
+	timeout := time.Duration(cfg.Timeout) * time.Second
+
+	switch cfg.Exporter {
+	case ExporterOTLPGRPC:
+		opts := []otlptracegrpc.Option{
+			otlptracegrpc.WithEndpoint(cfg.Endpoint),
+			otlptracegrpc.WithTimeout(timeout),
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		if len(cfg.Headers) > 0 {
+			opts = append(opts, otlptracegrpc.WithHeaders(cfg.Headers))
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	case ExporterOTLPHTTP:
+		opts := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(cfg.Endpoint),
+			otlptracehttp.WithTimeout(timeout),
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		if len(cfg.Headers) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(cfg.Headers))
+		}
+		return otlptracehttp.New(ctx, opts...)
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+	case ExporterMemory:
+		memory = tracetest.NewInMemoryExporter()
+		return memory, nil
+	}
+	return nil, fmt.Errorf("tracer: unknown exporter %q", cfg.Exporter)
+}
+
+// GetTracer 获取tracer实例，未启用时返回不采集的tracer
+func GetTracer() trace.Tracer {
 	return tracer
 }
 
-// Close 关闭tracer
-func Close() {
-	if closer != nil {
-		closer.Close()
+// MemoryExporter 获取内存导出器，仅在 exporter 为 memory 时可用
+func MemoryExporter() *tracetest.InMemoryExporter {
+	return memory
+}
+
+// Ping 检查tracer是否初始化成功以及OTLP接收端能否连接
+func Ping(ctx context.Context) error {
+	if provider == nil {
+		return errors.New("tracer: not initialized")
 	}
+	if endpoint == "" {
+		return nil
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", endpoint)
+	if err != nil {
+		return fmt.Errorf("tracer: connect %s: %w", endpoint, err)
+	}
+	return conn.Close()
 }
 
-// StartSpan 开始一个新的span
-func StartSpan(operationName string) opentracing.Span {
-	return tracer.StartSpan(operationName)
+// Shutdown 导出缓冲中的span后关闭
+func Shutdown(ctx context.Context) error {
+	if provider == nil {
+		return nil
+	}
+	return provider.Shutdown(ctx)
 }
 
-// StartSpanFromContext 从上下文开始一个新的span
-func StartSpanFromContext(ctx opentracing.SpanContext, operationName string) opentracing.Span {
-	return tracer.StartSpan(operationName, opentracing.ChildOf(ctx))
+// StartSpan 从上下文开始一个新的span，ctx中已有span时作为其子span
+func StartSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, opts...)
 }
@@ -0,0 +1,51 @@
+package tracer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ocean-marketing/internal/config"
+	"ocean-marketing/internal/pkg/logger"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "tracer-test")
+	if err != nil {
+		panic(err)
+	}
+	logger.Init(config.LogConfig{Level: "error", Format: "json", OutputPath: filepath.Join(dir, "app.log"), MaxSize: 1})
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestPingSkipsEndpointForLocalExporters(t *testing.T) {
+	tests := []struct {
+		name     string
+		exporter string
+	}{
+		{name: "stdout", exporter: ExporterStdout},
+		{name: "memory", exporter: ExporterMemory},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 端口不可连接，若被拨号则Ping失败
+			Init(config.TracerConfig{
+				Enabled:     true,
+				ServiceName: "ocean-marketing-test",
+				Exporter:    tt.exporter,
+				Endpoint:    "127.0.0.1:1",
+				SampleRate:  1,
+			})
+			t.Cleanup(func() { Shutdown(context.Background()) })
+
+			if err := Ping(context.Background()); err != nil {
+				t.Errorf("Ping() = %v, want nil", err)
+			}
+		})
+	}
+}
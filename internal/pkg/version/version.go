@@ -0,0 +1,46 @@
+package version
+
+import (
+	"runtime"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// 构建信息，通过 -ldflags "-X ocean-marketing/internal/pkg/version.Version=..." 注入
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+// 构建信息指标，值恒为1，通过标签关联部署版本
+var buildInfo = promauto.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "build_info",
+		Help: "Build information of the running binary, value is always 1",
+	},
+	[]string{"version", "commit", "build_time", "go_version"},
+)
+
+func init() {
+	buildInfo.WithLabelValues(Version, Commit, BuildTime, runtime.Version()).Set(1)
+}
+
+// Info 构建信息
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get 获取构建信息
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+}
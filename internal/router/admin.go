@@ -0,0 +1,30 @@
+package router
+
+import (
+	"ocean-marketing/internal/config"
+	"ocean-marketing/internal/handler"
+	"ocean-marketing/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterAdminRoutes 注册管理接口路由，需要管理员权限
+func RegisterAdminRoutes(v1 *gin.RouterGroup, cfg *config.Config) {
+	delayedMessageHandler := handler.NewDelayedMessageHandler()
+	jobHandler := handler.NewJobHandler()
+	logLevelHandler := handler.NewLogLevelHandler()
+
+	admin := v1.Group("/admin", middleware.AuthMiddleware(), middleware.RequireAdmin(cfg))
+	{
+		admin.GET("/delayed-messages", delayedMessageHandler.GetDelayedMessages)          // 延迟消息列表
+		admin.DELETE("/delayed-messages/:id", delayedMessageHandler.CancelDelayedMessage) // 取消延迟消息
+
+		admin.GET("/jobs", jobHandler.GetJobs)               // 任务列表
+		admin.GET("/jobs/:id", jobHandler.GetJob)            // 任务详情
+		admin.POST("/jobs/:id/retry", jobHandler.RetryJob)   // 重试任务
+		admin.POST("/jobs/:id/cancel", jobHandler.CancelJob) // 取消任务
+
+		admin.GET("/log-level", logLevelHandler.GetLogLevel) // 日志级别
+		admin.PUT("/log-level", logLevelHandler.SetLogLevel) // 调整日志级别
+	}
+}
@@ -0,0 +1,38 @@
+package router
+
+import (
+	"ocean-marketing/internal/config"
+	"ocean-marketing/internal/handler"
+
+	"github.com/gin-contrib/pprof"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+)
+
+// RegisterInternal 注册运维端点：Prometheus指标、pprof、Swagger文档、健康检查详情和日志级别，
+// 注册在独立端口或带 AdminAuth 的路由组上
+func RegisterInternal(r *gin.RouterGroup, cfg config.AdminConfig) {
+	logLevelHandler := handler.NewLogLevelHandler()
+
+	// Prometheus 指标端点
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	// 健康检查详情，包含各项检查的错误信息
+	r.GET("/debug/health", handler.HealthDetail)
+
+	// 日志级别
+	r.GET("/debug/log-level", logLevelHandler.GetLogLevel)
+	r.PUT("/debug/log-level", logLevelHandler.SetLogLevel)
+
+	// pprof 性能分析
+	if cfg.Pprof {
+		pprof.RouteRegister(r)
+	}
+
+	// Swagger 文档
+	if cfg.Swagger {
+		r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	}
+}
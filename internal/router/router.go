@@ -13,6 +13,7 @@ func Register(r *gin.Engine, cfg *config.Config) {
 	r.GET("/health", handler.Health)
 	r.GET("/ready", handler.Ready)
 	r.GET("/live", handler.Live)
+	r.GET("/version", handler.Version)
 
 	// API 路由组
 	api := r.Group("/api")
@@ -22,5 +23,6 @@ func Register(r *gin.Engine, cfg *config.Config) {
 
 		// 注册各模块路由
 		RegisterExampleRoutes(v1Group)
+		RegisterAdminRoutes(v1Group, cfg)
 	}
 }
@@ -1,15 +1,30 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"time"
 
+	"ocean-marketing/internal/config"
 	"ocean-marketing/internal/model"
 	"ocean-marketing/internal/pkg/database"
+	"ocean-marketing/internal/pkg/eventbus"
+	"ocean-marketing/internal/pkg/logger"
+	"ocean-marketing/internal/pkg/metrics"
+	"ocean-marketing/internal/pkg/outbox"
 	"ocean-marketing/pkg/errno"
 
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+var (
+	// 创建的示例数
+	examplesCreatedTotal = metrics.Counter("examples_created_total", "Total number of examples created")
+	// 更新的示例数
+	examplesUpdatedTotal = metrics.Counter("examples_updated_total", "Total number of examples updated")
+)
+
 // ExampleService 示例服务
 type ExampleService struct{}
 
@@ -19,11 +34,11 @@ func NewExampleService() *ExampleService {
 }
 
 // GetList 获取示例列表
-func (s *ExampleService) GetList(page, size int) ([]model.ExampleResponse, int64, error) {
+func (s *ExampleService) GetList(ctx context.Context, page, size int) ([]model.ExampleResponse, int64, error) {
 	var examples []model.Example
 	var total int64
 
-	db := database.GetDB().Model(&model.Example{})
+	db := database.WithContext(ctx).Model(&model.Example{})
 
 	// 计算总数
 	if err := db.Count(&total).Error; err != nil {
@@ -56,9 +71,9 @@ func (s *ExampleService) GetList(page, size int) ([]model.ExampleResponse, int64
 }
 
 // GetByID 根据ID获取示例
-func (s *ExampleService) GetByID(id uint) (*model.ExampleResponse, error) {
+func (s *ExampleService) GetByID(ctx context.Context, id uint) (*model.ExampleResponse, error) {
 	var example model.Example
-	if err := database.GetDB().First(&example, id).Error; err != nil {
+	if err := database.WithContext(ctx).First(&example, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errno.ErrResourceNotFound
 		}
@@ -80,7 +95,7 @@ func (s *ExampleService) GetByID(id uint) (*model.ExampleResponse, error) {
 }
 
 // Create 创建示例
-func (s *ExampleService) Create(req *model.ExampleCreateRequest, createdBy string) (*model.ExampleResponse, error) {
+func (s *ExampleService) Create(ctx context.Context, req *model.ExampleCreateRequest, createdBy string) (*model.ExampleResponse, error) {
 	example := &model.Example{
 		Title:       req.Title,
 		Description: req.Description,
@@ -89,17 +104,24 @@ func (s *ExampleService) Create(req *model.ExampleCreateRequest, createdBy strin
 		CreatedBy:   createdBy,
 	}
 
-	if err := database.GetDB().Create(example).Error; err != nil {
+	err := s.commitWithEvents(ctx, func(tx *gorm.DB) ([]eventbus.Event, error) {
+		if err := tx.Create(example).Error; err != nil {
+			return nil, err
+		}
+		return []eventbus.Event{model.NewExampleCreated(example)}, nil
+	})
+	if err != nil {
 		return nil, errno.ErrDatabase
 	}
+	examplesCreatedTotal.WithLabelValues().Inc()
 
-	return s.GetByID(example.ID)
+	return s.GetByID(ctx, example.ID)
 }
 
 // Update 更新示例
-func (s *ExampleService) Update(id uint, req *model.ExampleUpdateRequest, currentUser string) (*model.ExampleResponse, error) {
+func (s *ExampleService) Update(ctx context.Context, id uint, req *model.ExampleUpdateRequest, currentUser string) (*model.ExampleResponse, error) {
 	var example model.Example
-	if err := database.GetDB().First(&example, id).Error; err != nil {
+	if err := database.WithContext(ctx).First(&example, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errno.ErrResourceNotFound
 		}
@@ -112,6 +134,7 @@ func (s *ExampleService) Update(id uint, req *model.ExampleUpdateRequest, curren
 	}
 
 	// 更新字段
+	before := example
 	if req.Title != "" {
 		example.Title = req.Title
 	}
@@ -125,17 +148,34 @@ func (s *ExampleService) Update(id uint, req *model.ExampleUpdateRequest, curren
 		example.Sort = *req.Sort
 	}
 
-	if err := database.GetDB().Save(&example).Error; err != nil {
+	err := s.commitWithEvents(ctx, func(tx *gorm.DB) ([]eventbus.Event, error) {
+		if err := tx.Save(&example).Error; err != nil {
+			return nil, err
+		}
+
+		changes := model.DiffExample(&before, &example)
+		if len(changes) == 0 {
+			return nil, nil
+		}
+		return []eventbus.Event{model.ExampleUpdated{
+			ID:         example.ID,
+			Changes:    changes,
+			UpdatedBy:  currentUser,
+			OccurredAt: time.Now(),
+		}}, nil
+	})
+	if err != nil {
 		return nil, errno.ErrDatabase
 	}
+	examplesUpdatedTotal.WithLabelValues().Inc()
 
-	return s.GetByID(example.ID)
+	return s.GetByID(ctx, example.ID)
 }
 
 // Delete 删除示例
-func (s *ExampleService) Delete(id uint, currentUser string) error {
+func (s *ExampleService) Delete(ctx context.Context, id uint, currentUser string) error {
 	var example model.Example
-	if err := database.GetDB().First(&example, id).Error; err != nil {
+	if err := database.WithContext(ctx).First(&example, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return errno.ErrResourceNotFound
 		}
@@ -147,9 +187,51 @@ func (s *ExampleService) Delete(id uint, currentUser string) error {
 		return errno.ErrPermissionDenied
 	}
 
-	if err := database.GetDB().Delete(&example).Error; err != nil {
+	err := s.commitWithEvents(ctx, func(tx *gorm.DB) ([]eventbus.Event, error) {
+		if err := tx.Delete(&example).Error; err != nil {
+			return nil, err
+		}
+		return []eventbus.Event{model.ExampleDeleted{
+			ID:         example.ID,
+			DeletedBy:  currentUser,
+			OccurredAt: time.Now(),
+		}}, nil
+	})
+	if err != nil {
 		return errno.ErrDatabase
 	}
 
 	return nil
 }
+
+// commitWithEvents 在事务中执行写操作，开启 outbox.enabled 时把产生的领域事件写入发件箱，
+// 事务提交后再发布到进程内事件总线
+func (s *ExampleService) commitWithEvents(ctx context.Context, fn func(tx *gorm.DB) ([]eventbus.Event, error)) error {
+	var events []eventbus.Event
+	err := database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if events, err = fn(tx); err != nil {
+			return err
+		}
+
+		// 未开启发件箱时没有投递器投递和清理，不写入
+		if !config.Get().Outbox.Enabled {
+			return nil
+		}
+
+		outboxEvents, err := outbox.FromEvents(events...)
+		if err != nil {
+			return err
+		}
+		return outbox.Add(tx, outboxEvents...)
+	})
+	if err != nil {
+		return err
+	}
+
+	// 数据已提交，同步订阅者的错误只记录不回滚
+	if err := eventbus.Publish(ctx, events...); err != nil {
+		logger.FromContext(ctx).Error("领域事件处理失败", zap.Error(err))
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"ocean-marketing/internal/model"
+	"ocean-marketing/internal/pkg/database"
+	"ocean-marketing/pkg/errno"
+
+	"gorm.io/gorm"
+)
+
+// JobService 后台任务管理服务
+type JobService struct{}
+
+// NewJobService 创建后台任务管理服务实例
+func NewJobService() *JobService {
+	return &JobService{}
+}
+
+// GetList 获取任务列表，可按状态和名称过滤
+func (s *JobService) GetList(ctx context.Context, status, name string, page, size int) ([]model.Job, int64, error) {
+	var jobs []model.Job
+	var total int64
+
+	db := database.WithContext(ctx).Model(&model.Job{})
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	if name != "" {
+		db = db.Where("name = ?", name)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, errno.ErrDatabase
+	}
+
+	offset := (page - 1) * size
+	if err := db.Order("id DESC").Offset(offset).Limit(size).Find(&jobs).Error; err != nil {
+		return nil, 0, errno.ErrDatabase
+	}
+
+	return jobs, total, nil
+}
+
+// GetByID 根据ID获取任务
+func (s *JobService) GetByID(ctx context.Context, id uint) (*model.Job, error) {
+	var job model.Job
+	if err := database.WithContext(ctx).First(&job, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errno.ErrResourceNotFound
+		}
+		return nil, errno.ErrDatabase
+	}
+
+	return &job, nil
+}
+
+// Retry 重新执行失败或已取消的任务
+func (s *JobService) Retry(ctx context.Context, id uint) (*model.Job, error) {
+	result := database.WithContext(ctx).Model(&model.Job{}).
+		Where("id = ? AND status IN ?", id, []string{model.JobStatusFailed, model.JobStatusCancelled}).
+		Updates(map[string]interface{}{
+			"status":      model.JobStatusPending,
+			"attempts":    0,
+			"run_at":      time.Now(),
+			"finished_at": nil,
+		})
+	if result.Error != nil {
+		return nil, errno.ErrDatabase
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, s.stateError(ctx, id)
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+// Cancel 取消等待中或执行中的任务，执行中的任务由worker在下次续约时中止
+func (s *JobService) Cancel(ctx context.Context, id uint) (*model.Job, error) {
+	result := database.WithContext(ctx).Model(&model.Job{}).
+		Where("id = ? AND status IN ?", id, []string{model.JobStatusPending, model.JobStatusRunning}).
+		Updates(map[string]interface{}{
+			"status":      model.JobStatusCancelled,
+			"unique_key":  nil,
+			"finished_at": time.Now(),
+		})
+	if result.Error != nil {
+		return nil, errno.ErrDatabase
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, s.stateError(ctx, id)
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+// stateError 区分任务不存在与状态冲突
+func (s *JobService) stateError(ctx context.Context, id uint) error {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return err
+	}
+	return errno.ErrJobStateInvalid
+}
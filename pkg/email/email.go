@@ -1,16 +1,23 @@
 package email
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
+	"net"
+	"strconv"
 
 	"ocean-marketing/internal/config"
 	"ocean-marketing/internal/pkg/logger"
+	"ocean-marketing/internal/pkg/metrics"
 
 	"go.uber.org/zap"
 	"gopkg.in/gomail.v2"
 )
 
+// 邮件发送次数，result 为 success 或 failure
+var emailsSentTotal = metrics.Counter("emails_sent_total", "Total number of emails sent", "result")
+
 // Client 邮件客户端
 type Client struct {
 	cfg config.EmailConfig
@@ -21,6 +28,16 @@ func NewClient(cfg config.EmailConfig) *Client {
 	return &Client{cfg: cfg}
 }
 
+// Ping 检查SMTP服务器能否连接
+func (c *Client) Ping(ctx context.Context) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(c.cfg.Host, strconv.Itoa(c.cfg.Port)))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
 // SendEmail 发送邮件
 func (c *Client) SendEmail(to []string, subject, body string) error {
 	m := gomail.NewMessage()
@@ -43,9 +60,11 @@ func (c *Client) SendEmail(to []string, subject, body string) error {
 
 	// 发送邮件
 	if err := d.DialAndSend(m); err != nil {
+		emailsSentTotal.WithLabelValues("failure").Inc()
 		logger.Error("发送邮件失败", zap.Error(err), zap.Strings("to", to))
 		return err
 	}
+	emailsSentTotal.WithLabelValues("success").Inc()
 
 	logger.Info("邮件发送成功", zap.Strings("to", to), zap.String("subject", subject))
 	return nil
@@ -69,9 +88,11 @@ func (c *Client) SendPlainTextEmail(to []string, subject, textBody string) error
 	d.TLSConfig = &tls.Config{InsecureSkipVerify: true}
 
 	if err := d.DialAndSend(m); err != nil {
+		emailsSentTotal.WithLabelValues("failure").Inc()
 		logger.Error("发送纯文本邮件失败", zap.Error(err), zap.Strings("to", to))
 		return err
 	}
+	emailsSentTotal.WithLabelValues("success").Inc()
 
 	logger.Info("纯文本邮件发送成功", zap.Strings("to", to), zap.String("subject", subject))
 	return nil
@@ -95,9 +116,11 @@ func (c *Client) SendEmailWithAttachment(to []string, subject, body string, atta
 	d.TLSConfig = &tls.Config{InsecureSkipVerify: true}
 
 	if err := d.DialAndSend(m); err != nil {
+		emailsSentTotal.WithLabelValues("failure").Inc()
 		logger.Error("发送带附件邮件失败", zap.Error(err), zap.Strings("to", to))
 		return err
 	}
+	emailsSentTotal.WithLabelValues("success").Inc()
 
 	logger.Info("带附件邮件发送成功", zap.Strings("to", to), zap.String("subject", subject))
 	return nil
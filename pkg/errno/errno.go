@@ -49,7 +49,8 @@ var (
 	ErrResourceConflict     = Errno{Code: 40003, Message: "资源冲突"}
 
 	// 业务逻辑错误
-	ErrBusiness = Errno{Code: 50001, Message: "业务逻辑错误"}
+	ErrBusiness        = Errno{Code: 50001, Message: "业务逻辑错误"}
+	ErrJobStateInvalid = Errno{Code: 50002, Message: "任务当前状态不允许该操作"}
 )
 
 // New 创建新的错误码
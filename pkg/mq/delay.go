@@ -0,0 +1,247 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"ocean-marketing/internal/pkg/logger"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// 延迟消息模式
+const (
+	// DelayModePlugin 使用rabbitmq_delayed_message_exchange插件的x-delay头
+	DelayModePlugin = "plugin"
+	// DelayModeRedis 使用Redis有序集合调度，到期后再发布
+	DelayModeRedis = "redis"
+)
+
+// ErrDelayClaimed 延迟消息已到期并被认领发布，无法再取消
+var ErrDelayClaimed = errors.New("mq: delayed message already claimed for publishing")
+
+const (
+	delayQueueKey    = "mq:delay:queue"
+	delayMessagesKey = "mq:delay:messages"
+	// delayProcessingKey 已认领、正在发布的消息，分数为租约到期时间
+	delayProcessingKey = "mq:delay:processing"
+
+	delayPollInterval = time.Second
+	delayBatchSize    = 100
+	// delayLease 被认领的消息在此时间内未发布成功会被重新认领
+	delayLease = 30 * time.Second
+)
+
+// claimDueScript 认领到期消息：从等待队列移到处理中集合并设置租约，防止多个实例重复发布；
+// 租约到期仍未完成（发布失败或实例退出）的消息重新认领
+const claimDueScript = `
+local limit = tonumber(ARGV[3])
+local ids = redis.call("ZRANGEBYSCORE", KEYS[2], "-inf", ARGV[1], "LIMIT", 0, limit)
+if #ids < limit then
+	local due = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, limit - #ids)
+	for _, id in ipairs(due) do
+		redis.call("ZREM", KEYS[1], id)
+		table.insert(ids, id)
+	end
+end
+for _, id in ipairs(ids) do
+	redis.call("ZADD", KEYS[2], ARGV[2], id)
+end
+return ids`
+
+// DelayedMessage 等待发布的延迟消息
+type DelayedMessage struct {
+	Exchange   string    `json:"exchange"`
+	RoutingKey string    `json:"routing_key"`
+	Message    Message   `json:"message"`
+	DueAt      time.Time `json:"due_at"`
+	// TraceHeaders 登记时的trace上下文，到期发布时恢复
+	TraceHeaders map[string]string `json:"trace_headers,omitempty"`
+}
+
+// DelayScheduler 基于Redis有序集合的延迟消息调度器
+type DelayScheduler struct {
+	rdb *redis.Client
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewDelayScheduler 创建延迟消息调度器
+func NewDelayScheduler(rdb *redis.Client) *DelayScheduler {
+	return &DelayScheduler{rdb: rdb}
+}
+
+// Schedule 登记延迟消息，message.ID 用于取消，ctx中的trace上下文随消息保存
+func (s *DelayScheduler) Schedule(ctx context.Context, exchange, routingKey string, message Message, delay time.Duration) error {
+	dueAt := time.Now().Add(delay)
+
+	body, err := json.Marshal(DelayedMessage{
+		Exchange:   exchange,
+		RoutingKey: routingKey,
+		Message:    message,
+		DueAt:      dueAt,
+		// 到期发布的消息与登记时的请求属于同一trace
+		TraceHeaders: traceHeaders(ctx),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, delayMessagesKey, message.ID, body)
+		pipe.ZAdd(ctx, delayQueueKey, &redis.Z{Score: float64(dueAt.UnixMilli()), Member: message.ID})
+		return nil
+	})
+	return err
+}
+
+// Cancel 取消尚未发布的延迟消息，消息不存在时返回false，已到期被认领时返回 ErrDelayClaimed
+func (s *DelayScheduler) Cancel(ctx context.Context, id string) (bool, error) {
+	removed, err := s.rdb.ZRem(ctx, delayQueueKey, id).Result()
+	if err != nil {
+		return false, err
+	}
+	if removed == 0 {
+		// 认领时原子地移出等待队列，不在等待队列中但在处理中集合里说明已经来不及取消
+		if err := s.rdb.ZScore(ctx, delayProcessingKey, id).Err(); err == nil {
+			return false, ErrDelayClaimed
+		} else if err != redis.Nil {
+			return false, err
+		}
+		return false, nil
+	}
+
+	return true, s.rdb.HDel(ctx, delayMessagesKey, id).Err()
+}
+
+// List 按到期时间顺序列出等待中的延迟消息
+func (s *DelayScheduler) List(ctx context.Context, offset, limit int64) ([]DelayedMessage, int64, error) {
+	total, err := s.rdb.ZCard(ctx, delayQueueKey).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ids, err := s.rdb.ZRange(ctx, delayQueueKey, offset, offset+limit-1).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	messages, err := s.load(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]DelayedMessage, 0, len(messages))
+	for _, id := range ids {
+		if message, ok := messages[id]; ok {
+			result = append(result, message)
+		}
+	}
+	return result, total, nil
+}
+
+// load 批量读取消息内容
+func (s *DelayScheduler) load(ctx context.Context, ids []string) (map[string]DelayedMessage, error) {
+	result := make(map[string]DelayedMessage, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	values, err := s.rdb.HMGet(ctx, delayMessagesKey, ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, value := range values {
+		body, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		var message DelayedMessage
+		if err := json.Unmarshal([]byte(body), &message); err != nil {
+			logger.Named(logModule).Error("反序列化延迟消息失败", zap.Error(err), zap.String("message_id", ids[i]))
+			continue
+		}
+		result[ids[i]] = message
+	}
+	return result, nil
+}
+
+// start 启动到期消息的发布循环
+func (s *DelayScheduler) start(client *Client) {
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(delayPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				s.publishDue(client)
+			}
+		}
+	}()
+}
+
+// shutdown 停止发布循环
+func (s *DelayScheduler) shutdown() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	<-s.done
+	s.stop = nil
+}
+
+// publishDue 认领并发布到期消息
+func (s *DelayScheduler) publishDue(client *Client) {
+	ctx := context.Background()
+	now := time.Now()
+
+	ids, err := s.rdb.Eval(ctx, claimDueScript, []string{delayQueueKey, delayProcessingKey},
+		now.UnixMilli(), now.Add(delayLease).UnixMilli(), delayBatchSize).StringSlice()
+	if err != nil {
+		logger.Named(logModule).Error("认领到期延迟消息失败", zap.Error(err))
+		return
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	messages, err := s.load(ctx, ids)
+	if err != nil {
+		logger.Named(logModule).Error("读取延迟消息失败", zap.Error(err))
+		return
+	}
+
+	for _, id := range ids {
+		message, ok := messages[id]
+		if ok {
+			if err := client.Publish(contextFromHeaders(message.TraceHeaders), message.Exchange, message.RoutingKey, message.Message); err != nil {
+				// 保留在处理中集合，租约到期后重新认领
+				logger.Named(logModule).Error("发布到期延迟消息失败", zap.Error(err), zap.String("message_id", id))
+				continue
+			}
+		}
+
+		_, err := s.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.ZRem(ctx, delayProcessingKey, id)
+			pipe.HDel(ctx, delayMessagesKey, id)
+			return nil
+		})
+		if err != nil {
+			logger.Named(logModule).Error("删除已发布延迟消息失败", zap.Error(err), zap.String("message_id", id))
+		}
+	}
+}
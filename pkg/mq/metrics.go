@@ -0,0 +1,53 @@
+package mq
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// 发布结果标签
+const (
+	publishResultAck        = "ack"
+	publishResultNack       = "nack"
+	publishResultUnroutable = "unroutable"
+	publishResultTimeout    = "timeout"
+	publishResultError      = "error"
+)
+
+var (
+	// 消息发布总数
+	mqPublishTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mq_publish_total",
+			Help: "Total number of messages published to RabbitMQ",
+		},
+		[]string{"exchange", "result"},
+	)
+
+	// 消息发布到收到确认的耗时
+	mqPublishDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "mq_publish_duration_seconds",
+			Help:    "Duration from publish to broker confirmation in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"exchange"},
+	)
+
+	// 被拒绝并转入死信队列的消息数
+	mqConsumeRejected = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mq_consume_rejected_total",
+			Help: "Total number of consumed messages rejected to the dead letter queue",
+		},
+		[]string{"queue", "reason"},
+	)
+)
+
+// exchangeLabel 默认交换器没有名字，用固定值代替
+func exchangeLabel(exchange string) string {
+	if exchange == "" {
+		return "(default)"
+	}
+	return exchange
+}
@@ -1,28 +1,60 @@
 package mq
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"ocean-marketing/internal/config"
 	"ocean-marketing/internal/pkg/logger"
+	appRedis "ocean-marketing/internal/pkg/redis"
 
 	"github.com/streadway/amqp"
 	"go.uber.org/zap"
 )
 
+var (
+	// ErrNacked broker拒绝了消息
+	ErrNacked = errors.New("mq: message nacked by broker")
+	// ErrUnroutable mandatory消息没有匹配的队列
+	ErrUnroutable = errors.New("mq: message unroutable")
+	// ErrConfirmTimeout 等待broker确认超时
+	ErrConfirmTimeout = errors.New("mq: publish confirm timeout")
+)
+
 // Client 消息队列客户端
 type Client struct {
-	conn    *amqp.Connection
-	channel *amqp.Channel
-	cfg     config.MQConfig
+	conn *amqp.Connection
+	pool *channelPool
+	cfg  config.MQConfig
+
+	// Redis模式下的延迟消息调度器
+	delay *DelayScheduler
+
+	// 每个消费者独占一个channel
+	mu        sync.Mutex
+	consumers []*amqp.Channel
 }
 
+// logModule 模块日志名，级别可通过 log.modules.mq 单独调整
+const logModule = "mq"
+
+// 消息头
+const (
+	// HeaderSchemaVersion 消息数据的schema版本
+	HeaderSchemaVersion = "x-schema-version"
+	// ContentTypeJSON 消息体编码
+	ContentTypeJSON = "application/json"
+)
+
 // Message 消息结构
 type Message struct {
 	ID        string                 `json:"id"`
 	Type      string                 `json:"type"`
+	Version   int                    `json:"version,omitempty"`
 	Data      map[string]interface{} `json:"data"`
 	Timestamp int64                  `json:"timestamp"`
 	Retry     int                    `json:"retry"`
@@ -46,51 +78,75 @@ func (c *Client) connect() error {
 
 	conn, err := amqp.Dial(dsn)
 	if err != nil {
-		logger.Error("连接RabbitMQ失败", zap.Error(err))
+		logger.Named(logModule).Error("连接RabbitMQ失败", zap.Error(err))
 		return err
 	}
 
-	channel, err := conn.Channel()
+	c.conn = conn
+	c.pool = newChannelPool(conn, c.cfg.ChannelPoolSize)
+
+	if c.cfg.DelayMode == DelayModeRedis {
+		c.delay = NewDelayScheduler(appRedis.GetClient())
+		c.delay.start(c)
+	}
+
+	logger.Named(logModule).Info("RabbitMQ连接成功", zap.Int("channel_pool_size", c.pool.size))
+	return nil
+}
+
+// confirmTimeout 默认的发布确认超时
+func (c *Client) confirmTimeout() time.Duration {
+	if c.cfg.ConfirmTimeout > 0 {
+		return time.Duration(c.cfg.ConfirmTimeout) * time.Second
+	}
+	return 5 * time.Second
+}
+
+// withChannel 从通道池借出一个通道执行操作
+func (c *Client) withChannel(fn func(ch *amqp.Channel) error) error {
+	pc, err := c.pool.get(context.Background())
 	if err != nil {
-		logger.Error("创建RabbitMQ channel失败", zap.Error(err))
-		conn.Close()
 		return err
 	}
 
-	c.conn = conn
-	c.channel = channel
+	if err := fn(pc.ch); err != nil {
+		// 声明类操作失败时broker会关闭通道
+		c.pool.discard(pc)
+		return err
+	}
 
-	logger.Info("RabbitMQ连接成功")
+	c.pool.put(pc)
 	return nil
 }
 
-// Publish 发布消息
-func (c *Client) Publish(exchange, routingKey string, message Message) error {
+// Publish 发布消息，等待broker确认（超时时间取配置 mq.confirm_timeout），ctx中的trace上下文写入消息头
+func (c *Client) Publish(ctx context.Context, exchange, routingKey string, message Message) error {
+	return c.PublishConfirmed(ctx, exchange, routingKey, message, c.confirmTimeout())
+}
+
+// PublishConfirmed 发布消息并在timeout内等待broker的ack/nack
+// 消息以mandatory方式发布，无法路由时返回ErrUnroutable
+func (c *Client) PublishConfirmed(ctx context.Context, exchange, routingKey string, message Message, timeout time.Duration) error {
 	message.Timestamp = time.Now().Unix()
 
 	body, err := json.Marshal(message)
 	if err != nil {
-		logger.Error("序列化消息失败", zap.Error(err))
+		logger.Named(logModule).Error("序列化消息失败", zap.Error(err))
 		return err
 	}
 
-	err = c.channel.Publish(
-		exchange,   // exchange
-		routingKey, // routing key
-		false,      // mandatory
-		false,      // immediate
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        body,
-		},
-	)
+	err = c.publish(ctx, exchange, routingKey, true, newPublishing(message, body), timeout)
 
 	if err != nil {
-		logger.Error("发布消息失败", zap.Error(err))
+		logger.Named(logModule).Error("发布消息失败",
+			zap.Error(err),
+			zap.String("exchange", exchange),
+			zap.String("routing_key", routingKey),
+			zap.String("message_id", message.ID))
 		return err
 	}
 
-	logger.Info("消息发布成功",
+	logger.Named(logModule).Info("消息发布成功",
 		zap.String("exchange", exchange),
 		zap.String("routing_key", routingKey),
 		zap.String("message_id", message.ID))
@@ -98,35 +154,173 @@ func (c *Client) Publish(exchange, routingKey string, message Message) error {
 	return nil
 }
 
-// Subscribe 订阅消息
-func (c *Client) Subscribe(queueName string, handler func(Message) error) error {
+// newPublishing 构造消息信封，类型与schema版本同时写入消息头，便于不解析消息体路由
+func newPublishing(message Message, body []byte) amqp.Publishing {
+	headers := make(amqp.Table)
+	if message.Version > 0 {
+		headers[HeaderSchemaVersion] = int32(message.Version)
+	}
+
+	return amqp.Publishing{
+		ContentType:  ContentTypeJSON,
+		DeliveryMode: amqp.Persistent,
+		MessageId:    message.ID,
+		Type:         message.Type,
+		Headers:      headers,
+		Body:         body,
+	}
+}
+
+// publish 在池化通道上发布并等待确认
+func (c *Client) publish(ctx context.Context, exchange, routingKey string, mandatory bool, msg amqp.Publishing, timeout time.Duration) (err error) {
+	ctx, span := startPublishSpan(ctx, exchange, routingKey, &msg)
+	defer func() {
+		if err != nil {
+			recordError(span, err)
+		}
+		span.End()
+	}()
+
+	label := exchangeLabel(exchange)
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	pc, err := c.pool.get(ctx)
+	if err != nil {
+		mqPublishTotal.WithLabelValues(label, publishResultError).Inc()
+		return err
+	}
+
+	err = pc.ch.Publish(
+		exchange,   // exchange
+		routingKey, // routing key
+		mandatory,  // mandatory
+		false,      // immediate
+		msg,
+	)
+	if err != nil {
+		c.pool.discard(pc)
+		mqPublishTotal.WithLabelValues(label, publishResultError).Inc()
+		return err
+	}
+
+	select {
+	case confirm, ok := <-pc.confirms:
+		mqPublishDuration.WithLabelValues(label).Observe(time.Since(start).Seconds())
+
+		if !ok {
+			// 通道在确认前被关闭
+			c.pool.discard(pc)
+			mqPublishTotal.WithLabelValues(label, publishResultError).Inc()
+			return amqp.ErrClosed
+		}
+
+		// 无法路由的消息会先收到basic.return，再收到ack
+		var returned *amqp.Return
+		select {
+		case r := <-pc.returns:
+			returned = &r
+		default:
+		}
+		c.pool.put(pc)
+
+		if !confirm.Ack {
+			mqPublishTotal.WithLabelValues(label, publishResultNack).Inc()
+			return ErrNacked
+		}
+		if returned != nil {
+			mqPublishTotal.WithLabelValues(label, publishResultUnroutable).Inc()
+			return fmt.Errorf("%w: %d %s", ErrUnroutable, returned.ReplyCode, returned.ReplyText)
+		}
+
+		mqPublishTotal.WithLabelValues(label, publishResultAck).Inc()
+		return nil
+	case <-ctx.Done():
+		// 迟到的确认会错配给下一次发布，该通道不能再归还
+		c.pool.discard(pc)
+		mqPublishTotal.WithLabelValues(label, publishResultTimeout).Inc()
+		return ErrConfirmTimeout
+	}
+}
+
+// Subscribe 订阅消息，handler的ctx携带从消息头恢复的trace上下文
+func (c *Client) Subscribe(queueName string, handler func(ctx context.Context, message Message) error) error {
+	return c.consume(queueName, nil, func(ctx context.Context, d amqp.Delivery) error {
+		var message Message
+		if err := json.Unmarshal(d.Body, &message); err != nil {
+			logger.Named(logModule).Error("反序列化消息失败", zap.Error(err))
+			d.Nack(false, false)
+			return err
+		}
+
+		if err := handler(ctx, message); err != nil {
+			logger.Named(logModule).With(logger.ContextFields(ctx)...).Error("处理消息失败",
+				zap.Error(err),
+				zap.String("message_id", message.ID))
+
+			// 重试逻辑
+			if message.Retry < 3 {
+				message.Retry++
+				c.Publish(ctx, "", queueName, message)
+			}
+
+			d.Nack(false, false)
+			return err
+		}
+
+		d.Ack(false)
+		logger.Named(logModule).Info("消息处理成功", zap.String("message_id", message.ID))
+		return nil
+	})
+}
+
+// consume 声明队列并在独立的channel上逐条处理投递，每条投递在消费者span中处理，handle返回的错误记录到span
+func (c *Client) consume(queueName string, args amqp.Table, handle func(ctx context.Context, d amqp.Delivery) error) error {
+	// 消费者使用独立的channel，避免与发布方共享
+	channel, err := c.conn.Channel()
+	if err != nil {
+		logger.Named(logModule).Error("创建RabbitMQ channel失败", zap.Error(err))
+		return err
+	}
+
 	// 声明队列
-	_, err := c.channel.QueueDeclare(
+	_, err = channel.QueueDeclare(
 		queueName, // name
 		true,      // durable
 		false,     // delete when unused
 		false,     // exclusive
 		false,     // no-wait
-		nil,       // arguments
+		args,      // arguments
 	)
 	if err != nil {
-		logger.Error("声明队列失败", zap.Error(err))
+		var amqpErr *amqp.Error
+		if errors.As(err, &amqpErr) && amqpErr.Code == amqp.PreconditionFailed {
+			// 已存在的队列参数与本次声明不一致，如旧队列没有死信参数
+			logger.Named(logModule).Error("声明队列失败，已存在的队列参数不一致，可改用 mq.dead_letter_mode=policy 或新的队列名",
+				zap.Error(err), zap.String("queue", queueName))
+		} else {
+			logger.Named(logModule).Error("声明队列失败", zap.Error(err))
+		}
+		channel.Close()
 		return err
 	}
 
 	// 设置QoS
-	err = c.channel.Qos(
+	err = channel.Qos(
 		1,     // prefetch count
 		0,     // prefetch size
 		false, // global
 	)
 	if err != nil {
-		logger.Error("设置QoS失败", zap.Error(err))
+		logger.Named(logModule).Error("设置QoS失败", zap.Error(err))
+		channel.Close()
 		return err
 	}
 
 	// 消费消息
-	msgs, err := c.channel.Consume(
+	msgs, err := channel.Consume(
 		queueName, // queue
 		"",        // consumer
 		false,     // auto-ack
@@ -136,83 +330,87 @@ func (c *Client) Subscribe(queueName string, handler func(Message) error) error
 		nil,       // args
 	)
 	if err != nil {
-		logger.Error("消费消息失败", zap.Error(err))
+		logger.Named(logModule).Error("消费消息失败", zap.Error(err))
+		channel.Close()
 		return err
 	}
 
+	c.mu.Lock()
+	c.consumers = append(c.consumers, channel)
+	c.mu.Unlock()
+
 	go func() {
 		for d := range msgs {
-			var message Message
-			if err := json.Unmarshal(d.Body, &message); err != nil {
-				logger.Error("反序列化消息失败", zap.Error(err))
-				d.Nack(false, false)
-				continue
-			}
-
-			if err := handler(message); err != nil {
-				logger.Error("处理消息失败",
-					zap.Error(err),
-					zap.String("message_id", message.ID))
-
-				// 重试逻辑
-				if message.Retry < 3 {
-					message.Retry++
-					c.Publish("", queueName, message)
-				}
-
-				d.Nack(false, false)
-			} else {
-				d.Ack(false)
-				logger.Info("消息处理成功", zap.String("message_id", message.ID))
+			ctx, span := startConsumeSpan(queueName, d)
+			if err := handle(ctx, d); err != nil {
+				recordError(span, err)
 			}
+			span.End()
 		}
 	}()
 
-	logger.Info("开始消费消息", zap.String("queue", queueName))
+	logger.Named(logModule).Info("开始消费消息", zap.String("queue", queueName))
 	return nil
 }
 
 // DeclareExchange 声明交换器
 func (c *Client) DeclareExchange(name, kind string) error {
-	return c.channel.ExchangeDeclare(
-		name,  // name
-		kind,  // type
-		true,  // durable
-		false, // auto-deleted
-		false, // internal
-		false, // no-wait
-		nil,   // arguments
-	)
+	return c.withChannel(func(ch *amqp.Channel) error {
+		return ch.ExchangeDeclare(
+			name,  // name
+			kind,  // type
+			true,  // durable
+			false, // auto-deleted
+			false, // internal
+			false, // no-wait
+			nil,   // arguments
+		)
+	})
 }
 
 // DeclareQueue 声明队列
 func (c *Client) DeclareQueue(name string) error {
-	_, err := c.channel.QueueDeclare(
-		name,  // name
-		true,  // durable
-		false, // delete when unused
-		false, // exclusive
-		false, // no-wait
-		nil,   // arguments
-	)
-	return err
+	return c.withChannel(func(ch *amqp.Channel) error {
+		_, err := ch.QueueDeclare(
+			name,  // name
+			true,  // durable
+			false, // delete when unused
+			false, // exclusive
+			false, // no-wait
+			nil,   // arguments
+		)
+		return err
+	})
 }
 
 // BindQueue 绑定队列到交换器
 func (c *Client) BindQueue(queueName, exchangeName, routingKey string) error {
-	return c.channel.QueueBind(
-		queueName,    // queue name
-		routingKey,   // routing key
-		exchangeName, // exchange
-		false,        // no-wait
-		nil,          // arguments
-	)
+	return c.withChannel(func(ch *amqp.Channel) error {
+		return ch.QueueBind(
+			queueName,    // queue name
+			routingKey,   // routing key
+			exchangeName, // exchange
+			false,        // no-wait
+			nil,          // arguments
+		)
+	})
 }
 
 // Close 关闭连接
 func (c *Client) Close() error {
-	if c.channel != nil {
-		c.channel.Close()
+	if c.delay != nil {
+		c.delay.shutdown()
+	}
+
+	c.mu.Lock()
+	for _, channel := range c.consumers {
+		channel.Close()
+	}
+	c.consumers = nil
+	c.mu.Unlock()
+
+	if c.pool != nil {
+		c.pool.close()
 	}
 	if c.conn != nil {
 		return c.conn.Close()
@@ -225,43 +423,67 @@ func (c *Client) IsConnected() bool {
 	return c.conn != nil && !c.conn.IsClosed()
 }
 
+// Ping 检查连接状态
+func (c *Client) Ping(ctx context.Context) error {
+	if !c.IsConnected() {
+		return errors.New("mq: connection closed")
+	}
+	return nil
+}
+
 // Reconnect 重新连接
 func (c *Client) Reconnect() error {
 	c.Close()
 	return c.connect()
 }
 
-// PublishDelay 发布延迟消息（需要RabbitMQ延迟插件）
-func (c *Client) PublishDelay(exchange, routingKey string, message Message, delay time.Duration) error {
+// PublishDelay 发布延迟消息
+// plugin模式依赖RabbitMQ延迟插件；redis模式先登记到Redis，到期后再发布，可通过CancelDelay取消
+func (c *Client) PublishDelay(ctx context.Context, exchange, routingKey string, message Message, delay time.Duration) error {
+	if c.delay != nil {
+		return c.scheduleDelay(ctx, exchange, routingKey, message, delay)
+	}
+
 	message.Timestamp = time.Now().Unix()
 
 	body, err := json.Marshal(message)
 	if err != nil {
-		logger.Error("序列化延迟消息失败", zap.Error(err))
+		logger.Named(logModule).Error("序列化延迟消息失败", zap.Error(err))
 		return err
 	}
 
-	headers := make(amqp.Table)
-	headers["x-delay"] = int32(delay.Milliseconds())
+	// 延迟交换器不支持mandatory，这里只等待broker确认
+	publishing := newPublishing(message, body)
+	publishing.Headers["x-delay"] = int32(delay.Milliseconds())
 
-	err = c.channel.Publish(
-		exchange,   // exchange
-		routingKey, // routing key
-		false,      // mandatory
-		false,      // immediate
-		amqp.Publishing{
-			ContentType: "application/json",
-			Headers:     headers,
-			Body:        body,
-		},
-	)
+	err = c.publish(ctx, exchange, routingKey, false, publishing, c.confirmTimeout())
 
 	if err != nil {
-		logger.Error("发布延迟消息失败", zap.Error(err))
+		logger.Named(logModule).Error("发布延迟消息失败", zap.Error(err))
+		return err
+	}
+
+	logger.Named(logModule).Info("延迟消息发布成功",
+		zap.String("exchange", exchange),
+		zap.String("routing_key", routingKey),
+		zap.String("message_id", message.ID),
+		zap.Duration("delay", delay))
+
+	return nil
+}
+
+// scheduleDelay 在Redis中登记延迟消息
+func (c *Client) scheduleDelay(ctx context.Context, exchange, routingKey string, message Message, delay time.Duration) error {
+	if message.ID == "" {
+		message.ID = NewMessageID()
+	}
+
+	if err := c.delay.Schedule(ctx, exchange, routingKey, message, delay); err != nil {
+		logger.Named(logModule).Error("登记延迟消息失败", zap.Error(err))
 		return err
 	}
 
-	logger.Info("延迟消息发布成功",
+	logger.Named(logModule).Info("延迟消息登记成功",
 		zap.String("exchange", exchange),
 		zap.String("routing_key", routingKey),
 		zap.String("message_id", message.ID),
@@ -269,3 +491,11 @@ func (c *Client) PublishDelay(exchange, routingKey string, message Message, dela
 
 	return nil
 }
+
+// CancelDelay 取消尚未发布的延迟消息（仅redis模式）
+func (c *Client) CancelDelay(id string) (bool, error) {
+	if c.delay == nil {
+		return false, errors.New("mq: cancel requires delay_mode redis")
+	}
+	return c.delay.Cancel(context.Background(), id)
+}
@@ -0,0 +1,141 @@
+package mq
+
+import (
+	"context"
+	"sync"
+
+	"github.com/streadway/amqp"
+)
+
+// pooledChannel 处于confirm模式的发布通道
+type pooledChannel struct {
+	ch       *amqp.Channel
+	confirms chan amqp.Confirmation
+	returns  chan amqp.Return
+	closed   chan *amqp.Error
+}
+
+// isClosed 检查通道是否已被broker或连接关闭
+func (pc *pooledChannel) isClosed() bool {
+	select {
+	case <-pc.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// channelPool 发布通道池，保证同一时刻每个通道只被一个goroutine使用
+type channelPool struct {
+	conn *amqp.Connection
+	size int
+	idle chan *pooledChannel
+
+	mu   sync.Mutex
+	open int
+}
+
+// newChannelPool 创建通道池
+func newChannelPool(conn *amqp.Connection, size int) *channelPool {
+	if size <= 0 {
+		size = 1
+	}
+	return &channelPool{
+		conn: conn,
+		size: size,
+		idle: make(chan *pooledChannel, size),
+	}
+}
+
+// newChannel 创建新的confirm模式通道
+func (p *channelPool) newChannel() (*pooledChannel, error) {
+	ch, err := p.conn.Channel()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ch.Confirm(false); err != nil {
+		ch.Close()
+		return nil, err
+	}
+
+	// basic.return 先于 basic.ack 到达，缓冲为1即可避免阻塞分发
+	return &pooledChannel{
+		ch:       ch,
+		confirms: ch.NotifyPublish(make(chan amqp.Confirmation, 1)),
+		returns:  ch.NotifyReturn(make(chan amqp.Return, 1)),
+		closed:   ch.NotifyClose(make(chan *amqp.Error, 1)),
+	}, nil
+}
+
+// get 获取一个空闲通道，池满时等待归还
+func (p *channelPool) get(ctx context.Context) (*pooledChannel, error) {
+	for {
+		select {
+		case pc := <-p.idle:
+			if pc.isClosed() {
+				p.discard(pc)
+				continue
+			}
+			return pc, nil
+		default:
+		}
+
+		p.mu.Lock()
+		if p.open < p.size {
+			p.open++
+			p.mu.Unlock()
+
+			pc, err := p.newChannel()
+			if err != nil {
+				p.mu.Lock()
+				p.open--
+				p.mu.Unlock()
+				return nil, err
+			}
+			return pc, nil
+		}
+		p.mu.Unlock()
+
+		select {
+		case pc := <-p.idle:
+			if pc.isClosed() {
+				p.discard(pc)
+				continue
+			}
+			return pc, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// put 归还通道
+func (p *channelPool) put(pc *pooledChannel) {
+	if pc.isClosed() {
+		p.discard(pc)
+		return
+	}
+	p.idle <- pc
+}
+
+// discard 丢弃通道（超时未确认或已关闭的通道不能再复用）
+func (p *channelPool) discard(pc *pooledChannel) {
+	pc.ch.Close()
+
+	p.mu.Lock()
+	p.open--
+	p.mu.Unlock()
+}
+
+// close 关闭所有空闲通道
+func (p *channelPool) close() {
+	for {
+		select {
+		case pc := <-p.idle:
+			p.discard(pc)
+		default:
+			return
+		}
+	}
+}
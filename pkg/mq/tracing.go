@@ -0,0 +1,97 @@
+package mq
+
+import (
+	"context"
+
+	"ocean-marketing/internal/pkg/tracer"
+
+	"github.com/streadway/amqp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// headerCarrier 把AMQP消息头适配为 propagation.TextMapCarrier
+type headerCarrier amqp.Table
+
+// Get 读取消息头
+func (h headerCarrier) Get(key string) string {
+	value, _ := h[key].(string)
+	return value
+}
+
+// Set 写入消息头
+func (h headerCarrier) Set(key, value string) {
+	h[key] = value
+}
+
+// Keys 所有消息头名称
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// startPublishSpan 创建生产者span，并把trace上下文写入消息头
+func startPublishSpan(ctx context.Context, exchange, routingKey string, msg *amqp.Publishing) (context.Context, trace.Span) {
+	ctx, span := tracer.StartSpan(ctx, exchangeLabel(exchange)+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemRabbitmq,
+			semconv.MessagingOperationPublish,
+			semconv.MessagingDestinationName(exchangeLabel(exchange)),
+			semconv.MessagingRabbitmqDestinationRoutingKey(routingKey),
+			semconv.MessagingMessageID(msg.MessageId),
+		),
+	)
+
+	if msg.Headers == nil {
+		msg.Headers = make(amqp.Table)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier(msg.Headers))
+	return ctx, span
+}
+
+// startConsumeSpan 从消息头恢复上游trace上下文，创建消费者span
+func startConsumeSpan(queueName string, d amqp.Delivery) (context.Context, trace.Span) {
+	ctx := context.Background()
+	if d.Headers != nil {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, headerCarrier(d.Headers))
+	}
+
+	return tracer.StartSpan(ctx, queueName+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemRabbitmq,
+			semconv.MessagingOperationDeliver,
+			semconv.MessagingDestinationName(queueName),
+			semconv.MessagingRabbitmqDestinationRoutingKey(d.RoutingKey),
+			semconv.MessagingMessageID(d.MessageId),
+		),
+	)
+}
+
+// traceHeaders 以字符串形式导出trace上下文，用于随延迟消息保存
+func traceHeaders(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// contextFromHeaders 从保存的trace上下文恢复ctx
+func contextFromHeaders(headers map[string]string) context.Context {
+	return otel.GetTextMapPropagator().Extract(context.Background(), propagation.MapCarrier(headers))
+}
+
+// recordError 在span上记录错误
+func recordError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,330 @@
+package mq
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"ocean-marketing/internal/pkg/alert"
+	"ocean-marketing/internal/pkg/logger"
+
+	"github.com/streadway/amqp"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrUnknownType 消息类型未注册
+	ErrUnknownType = errors.New("mq: unknown message type")
+	// ErrUnsupportedVersion 消息版本无法升级到当前版本
+	ErrUnsupportedVersion = errors.New("mq: unsupported schema version")
+)
+
+// maxRetry 处理失败后的最大重试次数
+const maxRetry = 3
+
+// 死信配置方式
+const (
+	// DeadLetterModeArguments 声明队列时带 x-dead-letter-* 参数
+	DeadLetterModeArguments = "arguments"
+	// DeadLetterModePolicy 由RabbitMQ策略配置死信，队列声明不带参数
+	DeadLetterModePolicy = "policy"
+)
+
+// Upcaster 把某个版本的数据升级到下一个版本
+type Upcaster func(data map[string]interface{}) (map[string]interface{}, error)
+
+// messageType 已注册的消息类型
+type messageType struct {
+	name      string
+	version   int
+	goType    reflect.Type
+	upcasters map[int]Upcaster
+}
+
+// registry 消息类型注册表
+var registry = struct {
+	sync.RWMutex
+	byName map[string]*messageType
+	byType map[reflect.Type]*messageType
+}{
+	byName: make(map[string]*messageType),
+	byType: make(map[reflect.Type]*messageType),
+}
+
+// RegisterType 注册消息类型T及其当前schema版本（从1开始）
+func RegisterType[T any](name string, version int) {
+	if version < 1 {
+		panic(fmt.Sprintf("mq: invalid schema version %d for %s", version, name))
+	}
+
+	goType := reflect.TypeOf((*T)(nil)).Elem()
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, ok := registry.byName[name]; ok {
+		panic(fmt.Sprintf("mq: message type %s already registered", name))
+	}
+
+	mt := &messageType{
+		name:      name,
+		version:   version,
+		goType:    goType,
+		upcasters: make(map[int]Upcaster),
+	}
+	registry.byName[name] = mt
+	registry.byType[goType] = mt
+}
+
+// RegisterUpcaster 注册从fromVersion升级到fromVersion+1的转换函数
+func RegisterUpcaster(name string, fromVersion int, upcaster Upcaster) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	mt, ok := registry.byName[name]
+	if !ok {
+		panic(fmt.Sprintf("mq: message type %s not registered", name))
+	}
+	if fromVersion < 1 || fromVersion >= mt.version {
+		panic(fmt.Sprintf("mq: invalid upcaster version %d for %s (current %d)", fromVersion, name, mt.version))
+	}
+	mt.upcasters[fromVersion] = upcaster
+}
+
+// typeOf 查找T对应的已注册类型
+func typeOf[T any]() (*messageType, error) {
+	goType := reflect.TypeOf((*T)(nil)).Elem()
+
+	registry.RLock()
+	defer registry.RUnlock()
+
+	mt, ok := registry.byType[goType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, goType)
+	}
+	return mt, nil
+}
+
+// upcast 依次执行升级函数，把数据升级到当前版本
+func (mt *messageType) upcast(data map[string]interface{}, version int) (map[string]interface{}, error) {
+	if version > mt.version {
+		return nil, fmt.Errorf("%w: %s v%d is newer than v%d", ErrUnsupportedVersion, mt.name, version, mt.version)
+	}
+
+	for v := version; v < mt.version; v++ {
+		upcaster, ok := mt.upcasters[v]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s has no upcaster from v%d", ErrUnsupportedVersion, mt.name, v)
+		}
+
+		var err error
+		if data, err = upcaster(data); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// Delivery 类型化的消息投递
+type Delivery[T any] struct {
+	ID        string
+	Type      string
+	Version   int
+	Timestamp int64
+	Retry     int
+	Payload   T
+}
+
+// Publish 发布类型化消息，类型名与schema版本取自注册表
+func Publish[T any](ctx context.Context, c *Client, exchange, routingKey string, payload T) error {
+	mt, err := typeOf[T]()
+	if err != nil {
+		return err
+	}
+
+	data, err := toMap(payload)
+	if err != nil {
+		return err
+	}
+
+	return c.Publish(ctx, exchange, routingKey, Message{
+		ID:      NewMessageID(),
+		Type:    mt.name,
+		Version: mt.version,
+		Data:    data,
+	})
+}
+
+// Subscribe 订阅类型化消息
+// 旧版本消息会先经过升级函数；未注册类型、无法升级或无法解码的消息直接进入死信队列，
+// 处理失败的消息重试maxRetry次后进入死信队列；handler的ctx携带从消息头恢复的trace上下文
+func Subscribe[T any](c *Client, queueName string, handler func(ctx context.Context, d Delivery[T]) error) error {
+	mt, err := typeOf[T]()
+	if err != nil {
+		return err
+	}
+
+	dlq := DeadLetterQueue(queueName)
+	if err := c.DeclareQueue(dlq); err != nil {
+		logger.Named(logModule).Error("声明死信队列失败", zap.Error(err), zap.String("queue", dlq))
+		return err
+	}
+
+	// policy 模式下队列参数保持不变，死信由RabbitMQ策略配置，兼容已存在的队列
+	var args amqp.Table
+	if c.cfg.DeadLetterMode != DeadLetterModePolicy {
+		args = amqp.Table{
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": dlq,
+		}
+	}
+
+	return c.consume(queueName, args, func(ctx context.Context, d amqp.Delivery) error {
+		message, delivery, err := decode[T](mt, d)
+		if err != nil {
+			reject(ctx, queueName, d, err)
+			return err
+		}
+
+		if err := handler(ctx, delivery); err != nil {
+			logger.Named(logModule).With(logger.ContextFields(ctx)...).Error("处理消息失败",
+				zap.Error(err),
+				zap.String("queue", queueName),
+				zap.String("message_id", message.ID))
+
+			if message.Retry < maxRetry {
+				message.Retry++
+				if pubErr := c.Publish(ctx, "", queueName, message); pubErr == nil {
+					d.Ack(false)
+					return err
+				}
+			}
+
+			// 重试耗尽或重新发布失败，转入死信队列
+			mqConsumeRejected.WithLabelValues(queueName, "handler_failed").Inc()
+			alertDeadLetter(ctx, queueName, message.ID, "handler_failed", err)
+			d.Nack(false, false)
+			return err
+		}
+
+		d.Ack(false)
+		return nil
+	})
+}
+
+// DeadLetterQueue 类型化订阅使用的死信队列名
+func DeadLetterQueue(queueName string) string {
+	return queueName + ".dlq"
+}
+
+// decode 校验信封并把数据解码为T
+func decode[T any](mt *messageType, d amqp.Delivery) (Message, Delivery[T], error) {
+	var message Message
+	var delivery Delivery[T]
+
+	if d.ContentType != "" && d.ContentType != ContentTypeJSON {
+		return message, delivery, fmt.Errorf("mq: unsupported content type %q", d.ContentType)
+	}
+
+	if err := json.Unmarshal(d.Body, &message); err != nil {
+		return message, delivery, err
+	}
+
+	if message.Type != mt.name {
+		return message, delivery, fmt.Errorf("%w: %q (expected %q)", ErrUnknownType, message.Type, mt.name)
+	}
+
+	version := message.Version
+	if version == 0 {
+		if v, ok := d.Headers[HeaderSchemaVersion].(int32); ok {
+			version = int(v)
+		}
+	}
+	if version == 0 {
+		// 引入版本号之前发布的消息视为v1
+		version = 1
+	}
+
+	data, err := mt.upcast(message.Data, version)
+	if err != nil {
+		return message, delivery, err
+	}
+
+	body, err := json.Marshal(data)
+	if err != nil {
+		return message, delivery, err
+	}
+	if err := json.Unmarshal(body, &delivery.Payload); err != nil {
+		return message, delivery, err
+	}
+
+	delivery.ID = message.ID
+	delivery.Type = message.Type
+	delivery.Version = version
+	delivery.Timestamp = message.Timestamp
+	delivery.Retry = message.Retry
+	return message, delivery, nil
+}
+
+// reject 拒绝无法处理的消息，由broker转入死信队列
+func reject(ctx context.Context, queueName string, d amqp.Delivery, err error) {
+	reason := "decode_failed"
+	switch {
+	case errors.Is(err, ErrUnknownType):
+		reason = "unknown_type"
+	case errors.Is(err, ErrUnsupportedVersion):
+		reason = "unsupported_version"
+	}
+
+	logger.Named(logModule).Warn("消息被拒绝并转入死信队列",
+		zap.Error(err),
+		zap.String("queue", queueName),
+		zap.String("message_id", d.MessageId),
+		zap.String("reason", reason))
+
+	mqConsumeRejected.WithLabelValues(queueName, reason).Inc()
+	alertDeadLetter(ctx, queueName, d.MessageId, reason, err)
+	d.Nack(false, false)
+}
+
+// alertDeadLetter 消息转入死信队列时发送告警，同一队列在静默时间内只告警一次
+func alertDeadLetter(ctx context.Context, queueName, messageID, reason string, err error) {
+	alert.Send(ctx, alert.Alert{
+		Severity: alert.SeverityWarning,
+		Title:    "消息转入死信队列: " + queueName,
+		Content:  err.Error(),
+		Fields: []alert.Field{
+			alert.F("死信队列", DeadLetterQueue(queueName)),
+			alert.F("消息ID", messageID),
+			alert.F("原因", reason),
+		},
+	})
+}
+
+// toMap 把结构体按json标签展开为map
+func toMap(v interface{}) (map[string]interface{}, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// NewMessageID 生成随机消息ID
+func NewMessageID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,192 @@
+package mq
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/streadway/amqp"
+)
+
+// orderCreated 测试用消息，v1只有amount（分），v2改为price（元），v3增加currency
+type orderCreated struct {
+	OrderID  string  `json:"order_id"`
+	Price    float64 `json:"price"`
+	Currency string  `json:"currency"`
+}
+
+// gappedEvent 测试用消息，缺少从v1升级的函数
+type gappedEvent struct {
+	Name string `json:"name"`
+}
+
+func init() {
+	RegisterType[orderCreated]("test.order.created", 3)
+	RegisterUpcaster("test.order.created", 1, func(data map[string]interface{}) (map[string]interface{}, error) {
+		amount, ok := data["amount"].(float64)
+		if !ok {
+			return nil, fmt.Errorf("amount is required")
+		}
+		data["price"] = amount / 100
+		delete(data, "amount")
+		return data, nil
+	})
+	RegisterUpcaster("test.order.created", 2, func(data map[string]interface{}) (map[string]interface{}, error) {
+		data["currency"] = "CNY"
+		return data, nil
+	})
+
+	RegisterType[gappedEvent]("test.gapped", 2)
+}
+
+func TestDecodeUpcast(t *testing.T) {
+	mt, err := typeOf[orderCreated]()
+	if err != nil {
+		t.Fatalf("typeOf: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		body        string
+		headers     amqp.Table
+		contentType string
+		want        orderCreated
+		wantVersion int
+		wantErr     error
+	}{
+		{
+			name:        "当前版本",
+			body:        `{"id":"1","type":"test.order.created","version":3,"data":{"order_id":"o1","price":12.5,"currency":"USD"}}`,
+			want:        orderCreated{OrderID: "o1", Price: 12.5, Currency: "USD"},
+			wantVersion: 3,
+		},
+		{
+			name:        "v2升级到v3",
+			body:        `{"id":"2","type":"test.order.created","version":2,"data":{"order_id":"o2","price":8}}`,
+			want:        orderCreated{OrderID: "o2", Price: 8, Currency: "CNY"},
+			wantVersion: 2,
+		},
+		{
+			name:        "v1依次升级",
+			body:        `{"id":"3","type":"test.order.created","version":1,"data":{"order_id":"o3","amount":1999}}`,
+			want:        orderCreated{OrderID: "o3", Price: 19.99, Currency: "CNY"},
+			wantVersion: 1,
+		},
+		{
+			name:        "信封没有版本时取消息头",
+			body:        `{"id":"4","type":"test.order.created","data":{"order_id":"o4","price":1}}`,
+			headers:     amqp.Table{HeaderSchemaVersion: int32(2)},
+			want:        orderCreated{OrderID: "o4", Price: 1, Currency: "CNY"},
+			wantVersion: 2,
+		},
+		{
+			name:        "没有版本视为v1",
+			body:        `{"id":"5","type":"test.order.created","data":{"order_id":"o5","amount":100}}`,
+			contentType: ContentTypeJSON,
+			want:        orderCreated{OrderID: "o5", Price: 1, Currency: "CNY"},
+			wantVersion: 1,
+		},
+		{
+			name:    "比当前版本新",
+			body:    `{"id":"6","type":"test.order.created","version":4,"data":{}}`,
+			wantErr: ErrUnsupportedVersion,
+		},
+		{
+			name:    "类型不匹配",
+			body:    `{"id":"7","type":"test.order.cancelled","version":1,"data":{}}`,
+			wantErr: ErrUnknownType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, delivery, err := decode[orderCreated](mt, amqp.Delivery{
+				Body:        []byte(tt.body),
+				Headers:     tt.headers,
+				ContentType: tt.contentType,
+			})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("decode error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+
+			if delivery.Payload != tt.want {
+				t.Errorf("payload = %+v, want %+v", delivery.Payload, tt.want)
+			}
+			if delivery.Version != tt.wantVersion {
+				t.Errorf("version = %d, want %d", delivery.Version, tt.wantVersion)
+			}
+			if delivery.Type != "test.order.created" {
+				t.Errorf("type = %q, want test.order.created", delivery.Type)
+			}
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	orderType, _ := typeOf[orderCreated]()
+	gappedType, _ := typeOf[gappedEvent]()
+
+	decodeOrder := func(d amqp.Delivery) error {
+		_, _, err := decode[orderCreated](orderType, d)
+		return err
+	}
+	decodeGapped := func(d amqp.Delivery) error {
+		_, _, err := decode[gappedEvent](gappedType, d)
+		return err
+	}
+
+	tests := []struct {
+		name        string
+		decode      func(d amqp.Delivery) error
+		body        string
+		contentType string
+		wantErr     error
+	}{
+		{name: "缺少升级函数", decode: decodeGapped, body: `{"type":"test.gapped","version":1,"data":{"name":"a"}}`, wantErr: ErrUnsupportedVersion},
+		{name: "升级函数返回错误", decode: decodeOrder, body: `{"type":"test.order.created","version":1,"data":{"order_id":"o1"}}`},
+		{name: "非JSON内容类型", decode: decodeOrder, body: `{}`, contentType: "text/plain"},
+		{name: "无效JSON", decode: decodeOrder, body: `{"type":`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.decode(amqp.Delivery{Body: []byte(tt.body), ContentType: tt.contentType})
+			if err == nil {
+				t.Fatal("decode error = nil, want error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("decode error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegisterUpcasterInvalidVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		fromVersion int
+	}{
+		{name: "版本为0", fromVersion: 0},
+		{name: "等于当前版本", fromVersion: 3},
+		{name: "大于当前版本", fromVersion: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterUpcaster(%d) should panic", tt.fromVersion)
+				}
+			}()
+			RegisterUpcaster("test.order.created", tt.fromVersion, func(data map[string]interface{}) (map[string]interface{}, error) {
+				return data, nil
+			})
+		})
+	}
+}
@@ -8,11 +8,16 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// RequestIDKey gin上下文中保存请求ID的键，由请求ID中间件设置
+const RequestIDKey = "request_id"
+
 // Response 统一响应结构
 type Response struct {
 	Code    int         `json:"code"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
+	// RequestID 仅在错误响应中返回，便于按请求ID排查日志
+	RequestID string `json:"request_id,omitempty"`
 }
 
 // Success 成功响应
@@ -28,8 +33,9 @@ func Success(c *gin.Context, data interface{}) {
 func Error(c *gin.Context, err error) {
 	code, message := errno.DecodeErr(err)
 	c.JSON(http.StatusOK, Response{
-		Code:    code,
-		Message: message,
+		Code:      code,
+		Message:   message,
+		RequestID: c.GetString(RequestIDKey),
 	})
 }
 
@@ -37,18 +43,23 @@ func Error(c *gin.Context, err error) {
 func ErrorWithCode(c *gin.Context, httpCode int, err error) {
 	code, message := errno.DecodeErr(err)
 	c.JSON(httpCode, Response{
-		Code:    code,
-		Message: message,
+		Code:      code,
+		Message:   message,
+		RequestID: c.GetString(RequestIDKey),
 	})
 }
 
-// Custom 自定义响应
+// Custom 自定义响应，code不为OK时附带请求ID
 func Custom(c *gin.Context, httpCode int, code int, message string, data interface{}) {
-	c.JSON(httpCode, Response{
+	resp := Response{
 		Code:    code,
 		Message: message,
 		Data:    data,
-	})
+	}
+	if code != errno.OK.Code {
+		resp.RequestID = c.GetString(RequestIDKey)
+	}
+	c.JSON(httpCode, resp)
 }
 
 // BadRequest 400错误
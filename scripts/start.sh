@@ -48,8 +48,8 @@ echo "🎉 服务启动成功！"
 echo "📍 访问地址："
 echo "   - 应用: http://localhost:8080"
 echo "   - 健康检查: http://localhost:8080/health"
-echo "   - API文档: http://localhost:8080/swagger/index.html"
-echo "   - 监控指标: http://localhost:8080/metrics"
+echo "   - API文档: http://localhost:9091/swagger/index.html"
+echo "   - 监控指标: http://localhost:9091/metrics"
 echo ""
 echo "📋 可用API接口："
 echo "   - GET  /api/v1/examples          获取示例列表"
@@ -5,6 +5,8 @@
 set -e
 
 BASE_URL="http://localhost:8080"
+# 运维端点（admin.addr）
+ADMIN_URL="${ADMIN_URL:-http://localhost:9091}"
 
 echo "🧪 Ocean Marketing API 测试"
 echo "================================"
@@ -140,11 +142,14 @@ test_endpoint "POST" "/api/v1/examples" '{"invalid":json}' "400" "发送无效JS
 # 5. 监控和管理接口测试
 echo -e "\n📊 监控和管理接口测试"
 
+# 运维端点在独立端口，主端口不再提供
+test_endpoint "GET" "/metrics" "" "404" "主端口不提供Prometheus指标"
+
 # Prometheus指标
-test_endpoint "GET" "/metrics" "" "200" "Prometheus指标"
+BASE_URL="$ADMIN_URL" test_endpoint "GET" "/metrics" "" "200" "Prometheus指标"
 
 # Swagger文档（如果启用）
-test_endpoint "GET" "/swagger/index.html" "" "200" "Swagger文档"
+BASE_URL="$ADMIN_URL" test_endpoint "GET" "/swagger/index.html" "" "200" "Swagger文档"
 
 echo -e "\n${GREEN}🎉 API 测试完成！${NC}"
 echo "================================"